@@ -32,6 +32,61 @@ type ApplicationHealthStatus struct {
 	// Last revision when the application is healthy.
 	// +optional
 	LastHealthyRevision string `json:"lastHealthyRevision,omitempty"`
+
+	// Last revision when the dispatch event is sent.
+	// +optional
+	LastDispatchedRevision string `json:"lastDispatchedRevision,omitempty"`
+
+	// URL of the deployment created by the controller.
+	// +optional
+	DeploymentURL string `json:"deploymentURL,omitempty"`
+
+	// Revision of the deployment created by the controller.
+	// +optional
+	DeploymentRevision string `json:"deploymentRevision,omitempty"`
+
+	// Last revision when the progress deadline is exceeded.
+	// +optional
+	ProgressDeadlineExceededRevision string `json:"progressDeadlineExceededRevision,omitempty"`
+
+	// Last states of the deployment statuses created by the controller.
+	// This is a cache to reduce the GitHub API calls.
+	// +optional
+	DeploymentStates []DeploymentState `json:"deploymentStates,omitempty"`
+
+	// Deployments resolved from the deployment queries in the annotations.
+	// This is a cache to reduce the GitHub API calls.
+	// +optional
+	DeploymentResolutions []DeploymentResolution `json:"deploymentResolutions,omitempty"`
+}
+
+// DeploymentState represents the last state of the deployment status created by the controller.
+type DeploymentState struct {
+	// URL of the deployment.
+	URL string `json:"url"`
+
+	// State of the last deployment status.
+	State string `json:"state"`
+
+	// Revision of the application when the last deployment status is created.
+	Revision string `json:"revision"`
+
+	// Health status of the application when the last deployment status is created.
+	// It is empty if the deployment status is created on a sync operation phase.
+	// +optional
+	HealthStatus string `json:"healthStatus,omitempty"`
+}
+
+// DeploymentResolution represents a deployment resolved from the deployment query.
+type DeploymentResolution struct {
+	// Query of the deployment, such as repo=owner/repo,environment=pr-123.
+	Query string `json:"query"`
+
+	// Revision of the application when the deployment is resolved.
+	Revision string `json:"revision"`
+
+	// URL of the resolved deployment.
+	URL string `json:"url"`
 }
 
 // +kubebuilder:object:root=true
@@ -30,7 +30,7 @@ func (in *ApplicationHealth) DeepCopyInto(out *ApplicationHealth) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationHealth.
@@ -101,6 +101,16 @@ func (in *ApplicationHealthSpec) DeepCopy() *ApplicationHealthSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationHealthStatus) DeepCopyInto(out *ApplicationHealthStatus) {
 	*out = *in
+	if in.DeploymentStates != nil {
+		in, out := &in.DeploymentStates, &out.DeploymentStates
+		*out = make([]DeploymentState, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeploymentResolutions != nil {
+		in, out := &in.DeploymentResolutions, &out.DeploymentResolutions
+		*out = make([]DeploymentResolution, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationHealthStatus.
@@ -112,3 +122,33 @@ func (in *ApplicationHealthStatus) DeepCopy() *ApplicationHealthStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentResolution) DeepCopyInto(out *DeploymentResolution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentResolution.
+func (in *DeploymentResolution) DeepCopy() *DeploymentResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentState) DeepCopyInto(out *DeploymentState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentState.
+func (in *DeploymentState) DeepCopy() *DeploymentState {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentState)
+	in.DeepCopyInto(out)
+	return out
+}
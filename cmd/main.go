@@ -21,6 +21,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -193,7 +194,12 @@ func main() {
 		setupLog.Error(err, "unable to set up GitHub client")
 		os.Exit(1)
 	}
-	notificationClient := notification.NewClient(ghc)
+	deploymentStatusStatesOnHealth, err := notification.ParseDeploymentStatusStatesOnHealth(os.Getenv("DEPLOYMENT_STATUS_STATES_ON_HEALTH"))
+	if err != nil {
+		setupLog.Error(err, "invalid DEPLOYMENT_STATUS_STATES_ON_HEALTH")
+		os.Exit(1)
+	}
+	notificationClient := notification.NewClient(ghc, notification.ConfigMapTemplateLoader{Client: mgr.GetClient()}, deploymentStatusStatesOnHealth)
 
 	if err = (&controller.ApplicationPhaseCommentReconciler{
 		Client:       mgr.GetClient(),
@@ -222,10 +228,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	var defaultProgressDeadline time.Duration
+	if s := os.Getenv("PROGRESS_DEADLINE"); s != "" {
+		defaultProgressDeadline, err = time.ParseDuration(s)
+		if err != nil {
+			setupLog.Error(err, "invalid PROGRESS_DEADLINE")
+			os.Exit(1)
+		}
+	}
 	if err = (&controller.ApplicationHealthDeploymentReconciler{
-		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
-		Notification: notificationClient,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Notification:            notificationClient,
+		DefaultProgressDeadline: defaultProgressDeadline,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ApplicationHealthDeployment")
 		os.Exit(1)
@@ -239,6 +254,50 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ApplicationDeletionDeployment")
 		os.Exit(1)
 	}
+
+	if os.Getenv("FEATURE_SUMMARY_COMMENT") == "true" {
+		if err = (&controller.ApplicationSummaryCommentReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			Notification: notificationClient,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationSummaryComment")
+			os.Exit(1)
+		}
+	}
+
+	if os.Getenv("FEATURE_CHECK_RUN") == "true" {
+		if err = (&controller.ApplicationCheckRunReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			Notification: notificationClient,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationCheckRun")
+			os.Exit(1)
+		}
+	}
+
+	if os.Getenv("FEATURE_COMMIT_STATUS") == "true" {
+		if err = (&controller.ApplicationCommitStatusReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			Notification: notificationClient,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationCommitStatus")
+			os.Exit(1)
+		}
+	}
+
+	if os.Getenv("FEATURE_DEPLOYMENT_LOG") == "true" {
+		if err = (&controller.ApplicationDeploymentLogReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			Notification: notificationClient,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationDeploymentLog")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
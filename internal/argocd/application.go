@@ -1,7 +1,11 @@
 package argocd
 
 import (
+	"fmt"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
@@ -11,6 +15,15 @@ import (
 type SourceRevision struct {
 	Source   argocdv1alpha1.ApplicationSource
 	Revision string
+	// PreviousRevision is the revision deployed before the current sync operation.
+	// It is empty if not found in the history.
+	PreviousRevision string
+	// Rollback is true if the sync operation overrides the source, as Argo CD does on rollback.
+	Rollback bool
+	// Redeployed is true if the revision had been deployed before the previous deployment.
+	// It is a rollback only if the revision is an ancestor of the previous revision,
+	// which needs to be determined by the repository.
+	Redeployed bool
 }
 
 // GetSourceRevisions returns the last synced revisions
@@ -28,16 +41,67 @@ func GetSourceRevisions(app argocdv1alpha1.Application) []SourceRevision {
 	}
 	size := min(len(sources), len(revisions))
 
+	previousIndex := findPreviousHistoryIndex(app)
+	var previousRevisions []string
+	if previousIndex >= 0 {
+		previousRevisions = getHistoryRevisions(app.Status.History[previousIndex])
+	}
 	sourceRevisions := make([]SourceRevision, size)
 	for i := 0; i < size; i++ {
 		sourceRevisions[i] = SourceRevision{
 			Source:   sources[i],
 			Revision: revisions[i],
 		}
+		if i < len(previousRevisions) {
+			sourceRevisions[i].PreviousRevision = previousRevisions[i]
+			if revisions[i] != previousRevisions[i] {
+				sourceRevisions[i].Rollback = isRollbackOperation(app)
+				sourceRevisions[i].Redeployed = isRedeployed(app, previousIndex, i, revisions[i])
+			}
+		}
 	}
 	return sourceRevisions
 }
 
+// findPreviousHistoryIndex returns the index of the last deployment before the current sync operation.
+// The history contains the current sync operation if it has been completed, and it is skipped.
+// It returns -1 if not found.
+func findPreviousHistoryIndex(app argocdv1alpha1.Application) int {
+	for i := len(app.Status.History) - 1; i >= 0; i-- {
+		h := app.Status.History[i]
+		if h.DeployStartedAt != nil && h.DeployStartedAt.Equal(&app.Status.OperationState.StartedAt) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func getHistoryRevisions(h argocdv1alpha1.RevisionHistory) []string {
+	if h.Revisions != nil {
+		return h.Revisions
+	}
+	return []string{h.Revision}
+}
+
+// isRollbackOperation returns true if the sync operation overrides the source, as Argo CD does on rollback.
+func isRollbackOperation(app argocdv1alpha1.Application) bool {
+	sync := app.Status.OperationState.Operation.Sync
+	return sync.Source != nil || len(sync.Sources) > 0
+}
+
+// isRedeployed returns true if the revision has been deployed before the previous deployment,
+// such as auto-sync to a reset branch or a revert of the rollback.
+func isRedeployed(app argocdv1alpha1.Application, previousIndex, sourceIndex int, revision string) bool {
+	for _, h := range app.Status.History[:previousIndex] {
+		historyRevisions := getHistoryRevisions(h)
+		if sourceIndex < len(historyRevisions) && historyRevisions[sourceIndex] == revision {
+			return true
+		}
+	}
+	return false
+}
+
 // GetApplicationExternalURL returns the external URL if presents.
 func GetApplicationExternalURL(app argocdv1alpha1.Application) string {
 	if len(app.Status.Summary.ExternalURLs) == 0 {
@@ -54,12 +118,118 @@ func GetApplicationExternalURL(app argocdv1alpha1.Application) string {
 	return externalURL
 }
 
-// GetDeploymentURL returns the deployment URL in annotations
-func GetDeploymentURL(a argocdv1alpha1.Application) string {
+const deploymentURLAnnotation = "argocd-commenter.int128.github.io/deployment-url"
+
+// GetDeploymentURLs returns the deployment URLs in annotations.
+// The annotation may contain multiple URLs separated by comma or whitespace.
+// For a multi-source application, the annotation suffixed with the source name is also read,
+// such as argocd-commenter.int128.github.io/deployment-url.chart.
+// An annotation may contain a deployment query instead of URL, such as repo=owner/repo,environment=pr-123.
+func GetDeploymentURLs(a argocdv1alpha1.Application) []string {
+	var deploymentURLs []string
+	appendURL := func(s string) {
+		if s != "" && !slices.Contains(deploymentURLs, s) {
+			deploymentURLs = append(deploymentURLs, s)
+		}
+	}
+	value := strings.TrimSpace(a.Annotations[deploymentURLAnnotation])
+	if strings.Contains(value, "=") {
+		appendURL(value)
+	} else {
+		for _, s := range strings.FieldsFunc(value, isDeploymentURLSeparator) {
+			appendURL(s)
+		}
+	}
+	for _, source := range a.Spec.GetSources() {
+		if source.Name == "" {
+			continue
+		}
+		appendURL(strings.TrimSpace(a.Annotations[deploymentURLAnnotation+"."+source.Name]))
+	}
+	return deploymentURLs
+}
+
+func isDeploymentURLSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// GetDeploymentEnvironment returns the environment of the deployment to create in annotations
+func GetDeploymentEnvironment(a argocdv1alpha1.Application) string {
+	if a.Annotations == nil {
+		return ""
+	}
+	return a.Annotations["argocd-commenter.int128.github.io/deployment-environment"]
+}
+
+// IsProductionEnvironment returns true if the deployment to create is for the production environment
+func IsProductionEnvironment(a argocdv1alpha1.Application) bool {
+	return a.Annotations["argocd-commenter.int128.github.io/deployment-production-environment"] == "true"
+}
+
+// ShouldDeactivateSupersededDeployments returns false if the annotation is set to "false"
+func ShouldDeactivateSupersededDeployments(a argocdv1alpha1.Application) bool {
+	return a.Annotations["argocd-commenter.int128.github.io/deactivate-superseded-deployments"] != "false"
+}
+
+// IsTransientEnvironment returns true if the deployment to create is for a transient environment
+func IsTransientEnvironment(a argocdv1alpha1.Application) bool {
+	return a.Annotations["argocd-commenter.int128.github.io/deployment-transient-environment"] == "true"
+}
+
+// GetProgressDeadline returns the progress deadline in annotations.
+// It returns zero if the annotation is not set.
+func GetProgressDeadline(a argocdv1alpha1.Application) (time.Duration, error) {
+	s := a.Annotations["argocd-commenter.int128.github.io/progress-deadline"]
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid progress-deadline annotation: %w", err)
+	}
+	return d, nil
+}
+
+// GetEnvironment returns the environment name in annotations or labels
+func GetEnvironment(a argocdv1alpha1.Application) string {
+	const key = "argocd-commenter.int128.github.io/environment"
+	if env := a.Annotations[key]; env != "" {
+		return env
+	}
+	return a.Labels[key]
+}
+
+// GetDeploymentLogRepository returns the repository of the tracking issue in annotations,
+// such as owner/repo, or empty string if not set.
+func GetDeploymentLogRepository(a argocdv1alpha1.Application) string {
+	return a.Annotations["argocd-commenter.int128.github.io/deployment-log-repo"]
+}
+
+// GetProjectField returns the name of the project field and option in annotations.
+// If the option is not set, it returns the environment name as the option.
+func GetProjectField(a argocdv1alpha1.Application) (fieldName, optionName string) {
+	fieldName = a.Annotations["argocd-commenter.int128.github.io/project-field"]
+	optionName = a.Annotations["argocd-commenter.int128.github.io/project-field-option"]
+	if optionName == "" {
+		optionName = GetEnvironment(a)
+	}
+	return fieldName, optionName
+}
+
+// GetDispatch returns the target of dispatch event in annotations
+func GetDispatch(a argocdv1alpha1.Application) string {
+	if a.Annotations == nil {
+		return ""
+	}
+	return a.Annotations["argocd-commenter.int128.github.io/dispatch"]
+}
+
+// GetNotificationMode returns the notification mode in annotations
+func GetNotificationMode(a argocdv1alpha1.Application) string {
 	if a.Annotations == nil {
 		return ""
 	}
-	return a.Annotations["argocd-commenter.int128.github.io/deployment-url"]
+	return a.Annotations["argocd-commenter.int128.github.io/notification-mode"]
 }
 
 // GetSyncOperationPhase returns OperationState.Phase or empty string.
@@ -1,9 +1,12 @@
 package argocd
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetApplicationExternalURL(t *testing.T) {
@@ -53,3 +56,302 @@ func TestGetApplicationExternalURL(t *testing.T) {
 		}
 	})
 }
+
+func TestGetSourceRevisions(t *testing.T) {
+	startedAt := metav1.NewTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	previousStartedAt := metav1.NewTime(time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC))
+	newApplication := func(history argocdv1alpha1.RevisionHistories) argocdv1alpha1.Application {
+		return argocdv1alpha1.Application{
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Source: &argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/int128/sandbox"},
+			},
+			Status: argocdv1alpha1.ApplicationStatus{
+				OperationState: &argocdv1alpha1.OperationState{
+					StartedAt: startedAt,
+					Operation: argocdv1alpha1.Operation{
+						Sync: &argocdv1alpha1.SyncOperation{Revision: "bbbbbbb"},
+					},
+				},
+				History: history,
+			},
+		}
+	}
+
+	t.Run("No history", func(t *testing.T) {
+		sourceRevisions := GetSourceRevisions(newApplication(nil))
+		if len(sourceRevisions) != 1 {
+			t.Fatalf("len(sourceRevisions) wants 1 but got %d", len(sourceRevisions))
+		}
+		if sourceRevisions[0].PreviousRevision != "" {
+			t.Errorf("PreviousRevision wants empty but got %s", sourceRevisions[0].PreviousRevision)
+		}
+	})
+	t.Run("Sync operation is running", func(t *testing.T) {
+		sourceRevisions := GetSourceRevisions(newApplication(argocdv1alpha1.RevisionHistories{
+			{Revision: "aaaaaaa", DeployStartedAt: &previousStartedAt},
+		}))
+		if want := "aaaaaaa"; sourceRevisions[0].PreviousRevision != want {
+			t.Errorf("PreviousRevision wants %s but got %s", want, sourceRevisions[0].PreviousRevision)
+		}
+	})
+	t.Run("Sync operation is completed", func(t *testing.T) {
+		sourceRevisions := GetSourceRevisions(newApplication(argocdv1alpha1.RevisionHistories{
+			{Revision: "aaaaaaa", DeployStartedAt: &previousStartedAt},
+			{Revision: "bbbbbbb", DeployStartedAt: &startedAt},
+		}))
+		if want := "aaaaaaa"; sourceRevisions[0].PreviousRevision != want {
+			t.Errorf("PreviousRevision wants %s but got %s", want, sourceRevisions[0].PreviousRevision)
+		}
+		if sourceRevisions[0].Rollback {
+			t.Errorf("Rollback wants false but got true")
+		}
+	})
+	t.Run("Sync to a revision deployed before", func(t *testing.T) {
+		olderStartedAt := metav1.NewTime(time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC))
+		sourceRevisions := GetSourceRevisions(newApplication(argocdv1alpha1.RevisionHistories{
+			{Revision: "bbbbbbb", DeployStartedAt: &olderStartedAt},
+			{Revision: "ccccccc", DeployStartedAt: &previousStartedAt},
+		}))
+		if want := "ccccccc"; sourceRevisions[0].PreviousRevision != want {
+			t.Errorf("PreviousRevision wants %s but got %s", want, sourceRevisions[0].PreviousRevision)
+		}
+		if sourceRevisions[0].Rollback {
+			t.Errorf("Rollback wants false but got true")
+		}
+		if !sourceRevisions[0].Redeployed {
+			t.Errorf("Redeployed wants true but got false")
+		}
+	})
+	t.Run("Sync again after a rollback", func(t *testing.T) {
+		// The history is a, b, a (rollback) and then b is synced again.
+		oldestStartedAt := metav1.NewTime(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC))
+		olderStartedAt := metav1.NewTime(time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC))
+		sourceRevisions := GetSourceRevisions(newApplication(argocdv1alpha1.RevisionHistories{
+			{Revision: "aaaaaaa", DeployStartedAt: &oldestStartedAt},
+			{Revision: "bbbbbbb", DeployStartedAt: &olderStartedAt},
+			{Revision: "aaaaaaa", DeployStartedAt: &previousStartedAt},
+		}))
+		if want := "aaaaaaa"; sourceRevisions[0].PreviousRevision != want {
+			t.Errorf("PreviousRevision wants %s but got %s", want, sourceRevisions[0].PreviousRevision)
+		}
+		if sourceRevisions[0].Rollback {
+			t.Errorf("Rollback wants false but got true")
+		}
+		if !sourceRevisions[0].Redeployed {
+			t.Errorf("Redeployed wants true but got false")
+		}
+	})
+	t.Run("Rollback operation", func(t *testing.T) {
+		app := newApplication(argocdv1alpha1.RevisionHistories{
+			{Revision: "ccccccc", DeployStartedAt: &previousStartedAt},
+		})
+		app.Status.OperationState.Operation.Sync.Source = &argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/int128/sandbox"}
+		sourceRevisions := GetSourceRevisions(app)
+		if !sourceRevisions[0].Rollback {
+			t.Errorf("Rollback wants true but got false")
+		}
+	})
+}
+
+func TestGetEnvironment(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		env := GetEnvironment(argocdv1alpha1.Application{})
+		if env != "" {
+			t.Errorf("env wants empty but got %s", env)
+		}
+	})
+	t.Run("Label", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"argocd-commenter.int128.github.io/environment": "staging"},
+			},
+		}
+		env := GetEnvironment(app)
+		if want := "staging"; env != want {
+			t.Errorf("env wants %s but got %s", want, env)
+		}
+	})
+	t.Run("Annotation takes precedence over label", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"argocd-commenter.int128.github.io/environment": "production"},
+				Labels:      map[string]string{"argocd-commenter.int128.github.io/environment": "staging"},
+			},
+		}
+		env := GetEnvironment(app)
+		if want := "production"; env != want {
+			t.Errorf("env wants %s but got %s", want, env)
+		}
+	})
+}
+
+func TestGetProjectField(t *testing.T) {
+	t.Run("Option is set", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/project-field":        "Deployed to",
+					"argocd-commenter.int128.github.io/project-field-option": "Production",
+					"argocd-commenter.int128.github.io/environment":          "production",
+				},
+			},
+		}
+		fieldName, optionName := GetProjectField(app)
+		if fieldName != "Deployed to" || optionName != "Production" {
+			t.Errorf("GetProjectField wants (Deployed to, Production) but got (%s, %s)", fieldName, optionName)
+		}
+	})
+	t.Run("Option falls back to environment", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/project-field": "Deployed to",
+					"argocd-commenter.int128.github.io/environment":   "production",
+				},
+			},
+		}
+		fieldName, optionName := GetProjectField(app)
+		if fieldName != "Deployed to" || optionName != "production" {
+			t.Errorf("GetProjectField wants (Deployed to, production) but got (%s, %s)", fieldName, optionName)
+		}
+	})
+}
+
+func TestIsProductionEnvironment(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		if IsProductionEnvironment(argocdv1alpha1.Application{}) {
+			t.Errorf("IsProductionEnvironment wants false but got true")
+		}
+	})
+	t.Run("true", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"argocd-commenter.int128.github.io/deployment-production-environment": "true"},
+			},
+		}
+		if !IsProductionEnvironment(app) {
+			t.Errorf("IsProductionEnvironment wants true but got false")
+		}
+	})
+}
+
+func TestGetDeploymentURLs(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		deploymentURLs := GetDeploymentURLs(argocdv1alpha1.Application{})
+		if len(deploymentURLs) != 0 {
+			t.Errorf("deploymentURLs wants empty but got %v", deploymentURLs)
+		}
+	})
+	t.Run("Single URL", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/deployment-url": "https://api.github.com/repos/int128/sandbox/deployments/1",
+				},
+			},
+		}
+		deploymentURLs := GetDeploymentURLs(app)
+		want := []string{"https://api.github.com/repos/int128/sandbox/deployments/1"}
+		if !slices.Equal(deploymentURLs, want) {
+			t.Errorf("deploymentURLs wants %v but got %v", want, deploymentURLs)
+		}
+	})
+	t.Run("Multiple URLs and per-source keys", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/deployment-url": "https://api.github.com/repos/int128/sandbox/deployments/1,\n" +
+						"https://api.github.com/repos/int128/sandbox/deployments/2",
+					"argocd-commenter.int128.github.io/deployment-url.chart":  "https://api.github.com/repos/int128/chart/deployments/3",
+					"argocd-commenter.int128.github.io/deployment-url.values": "https://api.github.com/repos/int128/sandbox/deployments/1",
+				},
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Sources: argocdv1alpha1.ApplicationSources{
+					{Name: "chart", RepoURL: "https://github.com/int128/chart"},
+					{Name: "values", RepoURL: "https://github.com/int128/sandbox"},
+					{RepoURL: "https://github.com/int128/other"},
+				},
+			},
+		}
+		deploymentURLs := GetDeploymentURLs(app)
+		want := []string{
+			"https://api.github.com/repos/int128/sandbox/deployments/1",
+			"https://api.github.com/repos/int128/sandbox/deployments/2",
+			"https://api.github.com/repos/int128/chart/deployments/3",
+		}
+		if !slices.Equal(deploymentURLs, want) {
+			t.Errorf("deploymentURLs wants %v but got %v", want, deploymentURLs)
+		}
+	})
+	t.Run("Deployment query", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/deployment-url": "repo=int128/sandbox,environment=pr-123",
+				},
+			},
+		}
+		deploymentURLs := GetDeploymentURLs(app)
+		want := []string{"repo=int128/sandbox,environment=pr-123"}
+		if !slices.Equal(deploymentURLs, want) {
+			t.Errorf("deploymentURLs wants %v but got %v", want, deploymentURLs)
+		}
+	})
+}
+
+func TestShouldDeactivateSupersededDeployments(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		if !ShouldDeactivateSupersededDeployments(argocdv1alpha1.Application{}) {
+			t.Errorf("ShouldDeactivateSupersededDeployments wants true but was false")
+		}
+	})
+	t.Run("Opt-out", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/deactivate-superseded-deployments": "false",
+				},
+			},
+		}
+		if ShouldDeactivateSupersededDeployments(app) {
+			t.Errorf("ShouldDeactivateSupersededDeployments wants false but was true")
+		}
+	})
+}
+
+func TestGetProgressDeadline(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		d, err := GetProgressDeadline(argocdv1alpha1.Application{})
+		if err != nil {
+			t.Fatalf("GetProgressDeadline error: %s", err)
+		}
+		if d != 0 {
+			t.Errorf("progress deadline wants zero but got %s", d)
+		}
+	})
+	t.Run("Valid", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"argocd-commenter.int128.github.io/progress-deadline": "15m"},
+			},
+		}
+		d, err := GetProgressDeadline(app)
+		if err != nil {
+			t.Fatalf("GetProgressDeadline error: %s", err)
+		}
+		if want := 15 * time.Minute; d != want {
+			t.Errorf("progress deadline wants %s but got %s", want, d)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"argocd-commenter.int128.github.io/progress-deadline": "15"},
+			},
+		}
+		if _, err := GetProgressDeadline(app); err == nil {
+			t.Errorf("GetProgressDeadline wants error but got nil")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/controller/eventfilter"
+	"github.com/int128/argocd-commenter/internal/notification"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ApplicationCheckRunReconciler reconciles an Application object.
+// It creates or updates a check run when the sync operation phase or health status is changed.
+type ApplicationCheckRunReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	Notification notification.Client
+}
+
+//+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;watch;list
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;watch;list
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+func (r *ApplicationCheckRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var app argocdv1alpha1.Application
+	if err := r.Get(ctx, req.NamespacedName, &app); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !app.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	phase := argocd.GetSyncOperationPhase(app)
+	if phase == "" {
+		return ctrl.Result{}, nil
+	}
+
+	if after := getRequeueTimeToEvaluateHealthStatus(app); after > 0 {
+		logger.Info("Requeue later to evaluate the health status", "after", after,
+			"syncOperationFinishedAt", argocd.GetSyncOperationFinishedAt(app))
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+
+	argocdURL, err := argocd.GetExternalURL(ctx, r.Client, req.Namespace)
+	if err != nil {
+		logger.Info("unable to determine Argo CD URL", "error", err)
+	}
+
+	loadRelatedPulls := r.Notification.NewRelatedPullRequestsLoader(ctx, app)
+	if err := r.Notification.CreateOrUpdateCheckRuns(ctx, app, argocdURL, loadRelatedPulls); err != nil {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateCheckRunError",
+			"unable to create a check run on sync operation phase %s and health status %s: %s",
+			phase, app.Status.Health.Status, err)
+	} else {
+		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedCheckRun",
+			"created a check run on sync operation phase %s and health status %s",
+			phase, app.Status.Health.Status)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ApplicationCheckRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor("application-check-run")
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationCheckRun").
+		For(&argocdv1alpha1.Application{}).
+		WithEventFilter(eventfilter.ApplicationChanged(filterApplicationTransition)).
+		Complete(r)
+}
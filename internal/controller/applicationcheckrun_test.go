@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	"github.com/int128/argocd-commenter/internal/notification"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Check run", func() {
+	var app argocdv1alpha1.Application
+	var createCheckRun *githubmock.RecordRequests
+
+	BeforeEach(func(ctx context.Context) {
+		By("Starting the reconciler")
+		startManager(ctx, func(mgr ctrl.Manager, nc notification.Client) error {
+			return (&ApplicationCheckRunReconciler{
+				Client:       mgr.GetClient(),
+				Scheme:       mgr.GetScheme(),
+				Notification: nc,
+			}).SetupWithManager(mgr)
+		})
+
+		By("Setting up a check run endpoint")
+		createCheckRun = &githubmock.RecordRequests{}
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-check-run/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301/check-runs?check_name=fixture-check-run&filter=latest",
+			&githubmock.RecordRequests{Response: &github.ListCheckRunsResults{}},
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-check-run/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301/check-runs?check_name=fixture-check-run&filter=latest",
+			&githubmock.RecordRequests{Response: &github.ListCheckRunsResults{}},
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-check-run/check-runs",
+			createCheckRun,
+		)
+
+		By("Setting up a pull request endpoint")
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-check-run/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301/pulls",
+			&githubmock.RecordRequests{Response: []*github.PullRequest{{
+				Number: github.Ptr(301),
+				Head:   &github.PullRequestBranch{SHA: github.Ptr("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301")},
+			}}},
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-check-run/pulls/301/files",
+			githubmock.ListPullRequestFiles(),
+		)
+
+		By("Creating an application")
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fixture-check-run",
+				Namespace: "default",
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-check-run.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		DeferCleanup(func(ctx context.Context) {
+			Expect(k8sClient.Delete(ctx, &app)).Should(Succeed())
+		})
+	})
+
+	It("Should create a check run on the revision and the head of the pull request", func(ctx context.Context) {
+		By("Updating the application to running")
+		startedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:     synccommon.OperationRunning,
+			StartedAt: startedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createCheckRun.Count() }).Should(Equal(2))
+		Expect(createCheckRun.Bodies()).Should(ConsistOf(
+			SatisfyAll(
+				ContainSubstring(`"head_sha":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301"`),
+				ContainSubstring(`"status":"in_progress"`),
+			),
+			SatisfyAll(
+				ContainSubstring(`"head_sha":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301"`),
+				ContainSubstring(`"status":"in_progress"`),
+			),
+		))
+
+		By("Updating the application to succeeded")
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createCheckRun.Count() }).Should(Equal(4))
+		Expect(createCheckRun.Bodies()[2:]).Should(HaveEach(ContainSubstring(`"conclusion":"success"`)))
+
+		By("Updating a field unrelated to the transition")
+		app.Annotations = map[string]string{"example.com/unrelated": "true"}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Consistently(func() int { return createCheckRun.Count() }, 100*time.Millisecond).Should(Equal(4))
+	}, SpecTimeout(3*time.Second))
+
+	updateToFailed := func(ctx context.Context) {
+		By("Setting up a content endpoint")
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-check-run/contents/test/deployment.yaml?ref=bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301",
+			&githubmock.RecordRequests{Response: &github.RepositoryContent{
+				Type:    github.Ptr("file"),
+				Content: github.Ptr("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: foo\n"),
+			}},
+		)
+
+		By("Updating the application to failed")
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationFailed,
+			StartedAt:  metav1.Now(),
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				},
+			},
+			SyncResult: &argocdv1alpha1.SyncOperationResult{
+				Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				Resources: argocdv1alpha1.ResourceResults{
+					{
+						Kind:    "Deployment",
+						Name:    "foo",
+						Status:  synccommon.ResultCodeSyncFailed,
+						Message: "invalid spec",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createCheckRun.Count() }).Should(Equal(2))
+	}
+
+	It("Should annotate the manifest of the failed resource on the head of the pull request", func(ctx context.Context) {
+		updateToFailed(ctx)
+		Expect(createCheckRun.Bodies()).Should(ConsistOf(
+			SatisfyAll(
+				ContainSubstring(`"head_sha":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301"`),
+				ContainSubstring(`"conclusion":"failure"`),
+				Not(ContainSubstring(`"annotations"`)),
+			),
+			SatisfyAll(
+				ContainSubstring(`"head_sha":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301"`),
+				ContainSubstring(`"conclusion":"failure"`),
+				ContainSubstring(`"path":"test/deployment.yaml"`),
+				ContainSubstring(`"message":"invalid spec"`),
+			),
+		))
+	}, SpecTimeout(3*time.Second))
+
+	It("Should not annotate again when the check run is updated with the same conclusion", func(ctx context.Context) {
+		updateToFailed(ctx)
+
+		By("Setting up the created check runs")
+		updateCheckRun := &githubmock.RecordRequests{}
+		for id, sha := range map[int64]string{
+			1: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+			2: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb301",
+		} {
+			githubServer.Handle(
+				fmt.Sprintf("GET /api/v3/repos/owner/repo-check-run/commits/%s/check-runs?check_name=fixture-check-run&filter=latest", sha),
+				&githubmock.RecordRequests{Response: &github.ListCheckRunsResults{
+					Total: github.Ptr(1),
+					CheckRuns: []*github.CheckRun{{
+						ID:         github.Ptr(id),
+						Status:     github.Ptr("completed"),
+						Conclusion: github.Ptr("failure"),
+					}},
+				}},
+			)
+			githubServer.Handle(
+				fmt.Sprintf("PATCH /api/v3/repos/owner/repo-check-run/check-runs/%d", id),
+				updateCheckRun,
+			)
+		}
+
+		By("Updating the health status of the failed application")
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusDegraded,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return updateCheckRun.Count() }).Should(Equal(2))
+		Expect(updateCheckRun.Bodies()).Should(HaveEach(SatisfyAll(
+			ContainSubstring(`"conclusion":"failure"`),
+			Not(ContainSubstring(`"annotations"`)),
+		)))
+		Expect(createCheckRun.Count()).Should(Equal(2))
+	}, SpecTimeout(3*time.Second))
+})
@@ -7,7 +7,7 @@ import (
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
-	"github.com/int128/argocd-commenter/internal/controller/githubmock"
+	"github.com/int128/argocd-commenter/internal/githubmock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,11 +15,15 @@ import (
 
 var _ = Describe("Comment", func() {
 	var app argocdv1alpha1.Application
-	var createComment githubmock.CreateComment
+	var comments *githubmock.Comments
+	var createComment *githubmock.CreateComment
+	var editComment *githubmock.EditComment
 
 	BeforeEach(func(ctx context.Context) {
 		By("Setting up a comment endpoint")
-		createComment = githubmock.CreateComment{}
+		comments = &githubmock.Comments{}
+		createComment = &githubmock.CreateComment{Store: comments}
+		editComment = &githubmock.EditComment{Store: comments, ID: 1}
 		githubServer.Handle(
 			"GET /api/v3/repos/owner/repo-comment/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa101/pulls",
 			githubmock.ListPullRequestsWithCommit(101),
@@ -28,9 +32,17 @@ var _ = Describe("Comment", func() {
 			"GET /api/v3/repos/owner/repo-comment/pulls/101/files",
 			githubmock.ListPullRequestFiles(),
 		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-comment/issues/101/comments?per_page=100",
+			comments,
+		)
 		githubServer.Handle(
 			"POST /api/v3/repos/owner/repo-comment/issues/101/comments",
-			&createComment,
+			createComment,
+		)
+		githubServer.Handle(
+			"PATCH /api/v3/repos/owner/repo-comment/issues/comments/1",
+			editComment,
 		)
 
 		By("Creating an application")
@@ -88,7 +100,7 @@ var _ = Describe("Comment", func() {
 				},
 			}
 			Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-			Eventually(func() int { return createComment.Count() }).Should(Equal(2))
+			Eventually(func() int { return editComment.Count() }).Should(Equal(1))
 		})
 
 		Context("When the application is healthy", func() {
@@ -104,7 +116,17 @@ var _ = Describe("Comment", func() {
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Eventually(func() int { return createComment.Count() }).Should(Equal(3))
+				Eventually(func() int { return editComment.Count() }).Should(Equal(2))
+
+				By("Finding a single comment with the timeline")
+				Expect(createComment.Count()).Should(Equal(1))
+				Expect(comments.Bodies()).Should(ConsistOf(
+					SatisfyAll(
+						ContainSubstring("Syncing"),
+						ContainSubstring("Synced"),
+						ContainSubstring("Healthy"),
+					),
+				))
 			}, SpecTimeout(3*time.Second))
 
 			It("Should create healthy comment once", func(ctx context.Context) {
@@ -119,7 +141,7 @@ var _ = Describe("Comment", func() {
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Eventually(func() int { return createComment.Count() }).Should(Equal(3))
+				Eventually(func() int { return editComment.Count() }).Should(Equal(2))
 
 				By("Updating the application to progressing")
 				app.Status.Health = argocdv1alpha1.AppHealthStatus{
@@ -132,7 +154,7 @@ var _ = Describe("Comment", func() {
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Consistently(func() int { return createComment.Count() }, 100*time.Millisecond).Should(Equal(3))
+				Consistently(func() int { return editComment.Count() }, 100*time.Millisecond).Should(Equal(2))
 			}, SpecTimeout(3*time.Second))
 		})
 
@@ -149,7 +171,7 @@ var _ = Describe("Comment", func() {
 					Status: health.HealthStatusDegraded,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Eventually(func() int { return createComment.Count() }).Should(Equal(3))
+				Eventually(func() int { return editComment.Count() }).Should(Equal(2))
 			}, SpecTimeout(3*time.Second))
 		})
 	})
@@ -182,7 +204,7 @@ var _ = Describe("Comment", func() {
 				},
 			}
 			Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-			Consistently(func() int { return createComment.Count() }, 100*time.Millisecond).Should(Equal(1))
+			Consistently(func() int { return editComment.Count() }, 100*time.Millisecond).Should(Equal(0))
 
 			By("Updating the application to failed")
 			finishedAt := metav1.Now()
@@ -197,7 +219,7 @@ var _ = Describe("Comment", func() {
 				},
 			}
 			Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-			Eventually(func() int { return createComment.Count() }).Should(Equal(2))
+			Eventually(func() int { return editComment.Count() }).Should(Equal(1))
 		}, SpecTimeout(3*time.Second))
 	})
 
@@ -237,10 +259,10 @@ var _ = Describe("Comment", func() {
 				},
 			}
 			Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-			Eventually(func() int { return createComment.Count() }).Should(Equal(2))
+			Eventually(func() int { return editComment.Count() }).Should(Equal(1))
 
 			By("It should create a comment for healthy")
-			Eventually(func() int { return createComment.Count() }).WithTimeout(2 * time.Second).Should(Equal(3))
+			Eventually(func() int { return editComment.Count() }).WithTimeout(2 * time.Second).Should(Equal(2))
 		}, SpecTimeout(3*time.Second))
 	})
 })
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	"github.com/int128/argocd-commenter/internal/notification"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Commit status", func() {
+	var app argocdv1alpha1.Application
+	var createCommitStatus *githubmock.RecordRequests
+
+	BeforeEach(func(ctx context.Context) {
+		By("Starting the reconciler")
+		startManager(ctx, func(mgr ctrl.Manager, nc notification.Client) error {
+			return (&ApplicationCommitStatusReconciler{
+				Client:       mgr.GetClient(),
+				Scheme:       mgr.GetScheme(),
+				Notification: nc,
+			}).SetupWithManager(mgr)
+		})
+
+		By("Setting up a commit status endpoint")
+		createCommitStatus = &githubmock.RecordRequests{}
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-commit-status/statuses/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa302",
+			createCommitStatus,
+		)
+
+		By("Creating an application")
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fixture-commit-status",
+				Namespace: "default",
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-commit-status.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		DeferCleanup(func(ctx context.Context) {
+			Expect(k8sClient.Delete(ctx, &app)).Should(Succeed())
+		})
+	})
+
+	It("Should create a commit status on each transition", func(ctx context.Context) {
+		By("Updating the application to running")
+		startedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:     synccommon.OperationRunning,
+			StartedAt: startedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa302",
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createCommitStatus.Count() }).Should(Equal(1))
+		Expect(createCommitStatus.Bodies()[0]).Should(ContainSubstring(`"state":"pending"`))
+
+		By("Updating the application to succeeded")
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa302",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createCommitStatus.Count() }).Should(Equal(2))
+		Expect(createCommitStatus.Bodies()[1]).Should(ContainSubstring(`"state":"success"`))
+
+		By("Updating a field unrelated to the transition")
+		app.Annotations = map[string]string{"example.com/unrelated": "true"}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Consistently(func() int { return createCommitStatus.Count() }, 100*time.Millisecond).Should(Equal(2))
+	}, SpecTimeout(3*time.Second))
+})
@@ -21,6 +21,7 @@ import (
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
 	"github.com/int128/argocd-commenter/internal/argocd"
 	"github.com/int128/argocd-commenter/internal/controller/eventfilter"
 	"github.com/int128/argocd-commenter/internal/notification"
@@ -44,6 +45,7 @@ type ApplicationDeletionDeploymentReconciler struct {
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;watch;list
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;watch;list
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=argocdcommenter.int128.github.io,resources=applicationhealths,verbs=get;list;watch
 
 func (r *ApplicationDeletionDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -52,8 +54,13 @@ func (r *ApplicationDeletionDeploymentReconciler) Reconcile(ctx context.Context,
 	if err := r.Get(ctx, req.NamespacedName, &app); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	deploymentURL := argocd.GetDeploymentURL(app)
-	if deploymentURL == "" {
+	appHealth, err := getApplicationHealth(ctx, r.Client, app)
+	if err != nil {
+		logger.Error(err, "unable to get the ApplicationHealth")
+		return ctrl.Result{}, err
+	}
+	deploymentURLs := getDeploymentURLs(app, appHealth)
+	if len(deploymentURLs) == 0 {
 		return ctrl.Result{}, nil
 	}
 	if !isApplicationDeleting(app) {
@@ -65,12 +72,33 @@ func (r *ApplicationDeletionDeploymentReconciler) Reconcile(ctx context.Context,
 		logger.Info("unable to determine Argo CD URL", "error", err)
 	}
 
-	if err := r.Notification.CreateDeploymentStatusOnDeletion(ctx, app, argocdURL); err != nil {
-		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
-			"unable to create a deployment status on deletion: %s", err)
-	} else {
+	// The deployment queries have been resolved on sync.
+	deploymentURLs = findResolvedDeploymentURLs(app, appHealth, deploymentURLs, false)
+
+	var states []argocdcommenterv1.DeploymentState
+	for _, deploymentURL := range deploymentURLs {
+		state, err := r.Notification.CreateDeploymentStatusOnDeletion(ctx, app, argocdURL, deploymentURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
+				"unable to create a deployment status to %s on deletion: %s", deploymentURL, err)
+			continue
+		}
 		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeploymentStatus",
-			"created a deployment status on deletion")
+			"created a deployment status to %s on deletion", deploymentURL)
+		if state != "" {
+			states = append(states, argocdcommenterv1.DeploymentState{
+				URL:      deploymentURL,
+				State:    state,
+				Revision: getCurrentRevision(app),
+			})
+		}
+	}
+	// The ApplicationHealth is deleted with the Application.
+	if !app.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	if err := recordDeploymentStates(ctx, r.Client, r.Scheme, app, states); err != nil {
+		logger.Info("unable to record the deployment states", "error", err)
 	}
 	return ctrl.Result{}, nil
 }
@@ -96,7 +124,7 @@ func (r *ApplicationDeletionDeploymentReconciler) SetupWithManager(mgr ctrl.Mana
 }
 
 func filterApplicationDeletionForDeploymentStatus(appOld, appNew argocdv1alpha1.Application) bool {
-	if argocd.GetDeploymentURL(appNew) == "" {
+	if !hasDeployment(appNew) {
 		return false
 	}
 
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	"github.com/int128/argocd-commenter/internal/notification"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Deployment log", func() {
+	var app argocdv1alpha1.Application
+	var createIssue *githubmock.RecordRequests
+
+	BeforeEach(func(ctx context.Context) {
+		By("Starting the reconciler")
+		startManager(ctx, func(mgr ctrl.Manager, nc notification.Client) error {
+			return (&ApplicationDeploymentLogReconciler{
+				Client:       mgr.GetClient(),
+				Scheme:       mgr.GetScheme(),
+				Notification: nc,
+			}).SetupWithManager(mgr)
+		})
+
+		By("Setting up an issue endpoint")
+		createIssue = &githubmock.RecordRequests{Response: &github.Issue{Number: github.Ptr(1)}}
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-deployment-log/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa303/pulls",
+			githubmock.ListPullRequestsWithCommit(303),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-deployment-log/pulls/303/files",
+			githubmock.ListPullRequestFiles(),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-deployment-log/issues?state=open&labels=deployment-log%3Astaging&sort=created&direction=asc&per_page=100",
+			&githubmock.RecordRequests{Response: []*github.Issue{}},
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-deployment-log/issues",
+			createIssue,
+		)
+
+		By("Creating an application")
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fixture-deployment-log",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/environment": "staging",
+				},
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-deployment-log.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		DeferCleanup(func(ctx context.Context) {
+			Expect(k8sClient.Delete(ctx, &app)).Should(Succeed())
+		})
+	})
+
+	It("Should write the deployment log on each transition", func(ctx context.Context) {
+		By("Updating the application to running")
+		startedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:     synccommon.OperationRunning,
+			StartedAt: startedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa303",
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createIssue.Count() }).Should(Equal(1))
+		Expect(createIssue.Bodies()[0]).Should(SatisfyAll(
+			ContainSubstring(`deployment-log:staging`),
+			ContainSubstring(`#303`),
+			ContainSubstring(`Running`),
+		))
+
+		By("Updating the application to succeeded")
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa303",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int { return createIssue.Count() }).Should(Equal(2))
+		Expect(createIssue.Bodies()[1]).Should(ContainSubstring(`Healthy`))
+
+		By("Updating a field unrelated to the transition")
+		app.Annotations["example.com/unrelated"] = "true"
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Consistently(func() int { return createIssue.Count() }, 100*time.Millisecond).Should(Equal(2))
+	}, SpecTimeout(3*time.Second))
+})
@@ -8,7 +8,7 @@ import (
 	"github.com/argoproj/gitops-engine/pkg/health"
 	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/google/go-github/v80/github"
-	"github.com/int128/argocd-commenter/internal/controller/githubmock"
+	"github.com/int128/argocd-commenter/internal/githubmock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -102,13 +102,14 @@ var _ = Describe("Deployment status", func() {
 					Status: health.HealthStatusProgressing,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(3))
 
 				By("Updating the application to healthy")
 				app.Status.Health = argocdv1alpha1.AppHealthStatus{
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(3))
+				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(4))
 			}, SpecTimeout(3*time.Second))
 
 			It("Should not create any deployment status after healthy", func(ctx context.Context) {
@@ -117,13 +118,14 @@ var _ = Describe("Deployment status", func() {
 					Status: health.HealthStatusProgressing,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(3))
 
 				By("Updating the application to healthy")
 				app.Status.Health = argocdv1alpha1.AppHealthStatus{
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(3))
+				Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(4))
 
 				// The controller depends on the deployment status to deduplicate the health status.
 				listDeploymentStatus.Response = []*github.DeploymentStatus{
@@ -141,7 +143,7 @@ var _ = Describe("Deployment status", func() {
 					Status: health.HealthStatusHealthy,
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Consistently(func() int { return createDeploymentStatus.Count() }, "100ms").Should(Equal(3))
+				Consistently(func() int { return createDeploymentStatus.Count() }, "100ms").Should(Equal(4))
 
 				By("Updating the application to running")
 				startedAt := metav1.Now()
@@ -169,7 +171,7 @@ var _ = Describe("Deployment status", func() {
 					},
 				}
 				Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
-				Consistently(func() int { return createDeploymentStatus.Count() }, "100ms").Should(Equal(3))
+				Consistently(func() int { return createDeploymentStatus.Count() }, "100ms").Should(Equal(4))
 			}, SpecTimeout(3*time.Second))
 		})
 	})
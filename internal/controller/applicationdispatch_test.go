@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Dispatch", func() {
+	var app argocdv1alpha1.Application
+	var dispatchRequests atomic.Int32
+
+	BeforeEach(func(ctx context.Context) {
+		By("Setting up a dispatch endpoint which fails at first")
+		dispatchRequests.Store(0)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-dispatch/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa401/pulls",
+			&githubmock.RecordRequests{Response: []*github.PullRequest{}},
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-dispatch/dispatches",
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if dispatchRequests.Add(1) == 1 {
+					http.Error(w, "temporary error", http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		)
+
+		By("Creating an application")
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "fixture-dispatch-",
+				Namespace:    "default",
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/dispatch": "repo=owner/repo-dispatch,event-type=smoke-test",
+				},
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-dispatch.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+	})
+
+	It("Should retry the dispatch event until it succeeds", func(ctx context.Context) {
+		By("Updating the application to succeeded and healthy")
+		startedAt := metav1.Now()
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa401",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() int32 { return dispatchRequests.Load() }).Should(BeEquivalentTo(2))
+
+		By("Sending the dispatch event once per revision")
+		Consistently(func() int32 { return dispatchRequests.Load() }, 100*time.Millisecond).Should(BeEquivalentTo(2))
+	}, SpecTimeout(3*time.Second))
+})
@@ -97,6 +97,10 @@ func (r *ApplicationHealthCommentReconciler) Reconcile(ctx context.Context, req
 	}
 	currentRevision := sourceRevisions[0].Revision
 	if appHealth.Status.LastHealthyRevision == currentRevision {
+		if app.Status.Health.Status == health.HealthStatusHealthy && isDispatchPending(app, appHealth, currentRevision) {
+			logger.Info("retry the dispatch event of the healthy revision", "revision", currentRevision)
+			return r.createDispatch(ctx, app, appHealth, currentRevision, r.Notification.NewRelatedPullRequestsLoader(ctx, app))
+		}
 		logger.Info("current revision is already healthy", "revision", currentRevision)
 		return ctrl.Result{}, nil
 	}
@@ -125,12 +129,27 @@ func (r *ApplicationHealthCommentReconciler) Reconcile(ctx context.Context, req
 		logger.Info("unable to determine Argo CD URL", "error", err)
 	}
 
+	// The hooks share the pull requests listed at most once.
+	loadRelatedPulls := r.Notification.NewRelatedPullRequestsLoader(ctx, app)
 	if err := r.Notification.CreateCommentsOnHealthChanged(ctx, app, argocdURL); err != nil {
 		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateCommentError",
 			"unable to create a comment on health status %s: %s", app.Status.Health.Status, err)
 	} else {
 		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedComment",
 			"created a comment on health status %s", app.Status.Health.Status)
+
+		if err := r.Notification.UpdateDeploymentLabels(ctx, app, loadRelatedPulls); err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "UpdateLabelError",
+				"unable to update the labels on health status %s: %s", app.Status.Health.Status, err)
+		}
+		if err := r.Notification.NotifyClosingIssues(ctx, app, argocdURL, loadRelatedPulls); err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "NotifyClosingIssueError",
+				"unable to notify the closing issues on health status %s: %s", app.Status.Health.Status, err)
+		}
+		if err := r.Notification.UpdateProjectFields(ctx, app, loadRelatedPulls); err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "UpdateProjectFieldError",
+				"unable to update the project fields on health status %s: %s", app.Status.Health.Status, err)
+		}
 	}
 
 	if app.Status.Health.Status != health.HealthStatusHealthy {
@@ -144,6 +163,45 @@ func (r *ApplicationHealthCommentReconciler) Reconcile(ctx context.Context, req
 	}
 	r.Recorder.Eventf(&appHealth, corev1.EventTypeNormal, "UpdatedLastHealthyRevision",
 		"patched lastHealthyRevision to %s", currentRevision)
+
+	if isDispatchPending(app, appHealth, currentRevision) {
+		return r.createDispatch(ctx, app, appHealth, currentRevision, loadRelatedPulls)
+	}
+	return ctrl.Result{}, nil
+}
+
+// isDispatchPending returns true if the dispatch event of the revision has not been sent yet.
+func isDispatchPending(app argocdv1alpha1.Application, appHealth argocdcommenterv1.ApplicationHealth, revision string) bool {
+	return argocd.GetDispatch(app) != "" && appHealth.Status.LastDispatchedRevision != revision
+}
+
+// createDispatch sends a dispatch event once per revision.
+// If it failed, it returns the error to retry, because lastHealthyRevision is already patched.
+// If the annotation is invalid, it records an event without retry.
+func (r *ApplicationHealthCommentReconciler) createDispatch(ctx context.Context, app argocdv1alpha1.Application,
+	appHealth argocdcommenterv1.ApplicationHealth, revision string, loadRelatedPulls notification.RelatedPullRequestsLoader) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if annotation := argocd.GetDispatch(app); !notification.IsValidDispatch(annotation) {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "InvalidDispatch",
+			"invalid dispatch annotation: %s", annotation)
+		return ctrl.Result{}, nil
+	}
+	if err := r.Notification.CreateDispatch(ctx, app, loadRelatedPulls); err != nil {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDispatchError",
+			"unable to create a dispatch event: %s", err)
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDispatch",
+		"created a dispatch event at revision %s", revision)
+
+	patch := client.MergeFrom(appHealth.DeepCopy())
+	appHealth.Status.LastDispatchedRevision = revision
+	if err := r.Client.Status().Patch(ctx, &appHealth, patch); err != nil {
+		logger.Error(err, "unable to patch lastDispatchedRevision")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	r.Recorder.Eventf(&appHealth, corev1.EventTypeNormal, "UpdatedLastDispatchedRevision",
+		"patched lastDispatchedRevision to %s", revision)
 	return ctrl.Result{}, nil
 }
 
@@ -18,11 +18,14 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"slices"
 	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
 	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
 	"github.com/int128/argocd-commenter/internal/argocd"
 	"github.com/int128/argocd-commenter/internal/controller/eventfilter"
 	"github.com/int128/argocd-commenter/internal/notification"
@@ -53,6 +56,10 @@ type ApplicationHealthDeploymentReconciler struct {
 	Scheme       *runtime.Scheme
 	Recorder     record.EventRecorder
 	Notification notification.Client
+
+	// DefaultProgressDeadline is the progress deadline of an Application without the annotation.
+	// If zero, the progress deadline is disabled by default.
+	DefaultProgressDeadline time.Duration
 }
 
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;watch;list
@@ -71,28 +78,42 @@ func (r *ApplicationHealthDeploymentReconciler) Reconcile(ctx context.Context, r
 	if !app.DeletionTimestamp.IsZero() {
 		return ctrl.Result{}, nil
 	}
-	deploymentURL := argocd.GetDeploymentURL(app)
-	if deploymentURL == "" {
-		return ctrl.Result{}, nil
+	// On rollback, the deployments in the annotations belong to the revision rolled back from,
+	// and the phase reconciler has already updated them.
+	if len(argocd.GetDeploymentURLs(app)) > 0 {
+		rollback, err := r.Notification.IsRollback(ctx, app)
+		if err != nil {
+			logger.Error(err, "unable to determine if the sync operation is a rollback")
+			return ctrl.Result{}, err
+		}
+		if rollback {
+			return ctrl.Result{}, nil
+		}
 	}
-
-	deploymentIsAlreadyHealthy, err := r.Notification.CheckIfDeploymentIsAlreadyHealthy(ctx, deploymentURL)
-	if notification.IsNotFoundError(err) {
-		// Retry until the application is synced with a valid GitHub Deployment.
-		// https://github.com/int128/argocd-commenter/issues/762
-		lastOperationAt := argocd.GetLastOperationAt(app).Time
-		if time.Since(lastOperationAt) < requeueTimeoutWhenDeploymentNotFound {
-			r.Recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentNotFound",
-				"deployment %s not found, retry after %s", deploymentURL, requeueIntervalWhenDeploymentNotFound)
-			return ctrl.Result{RequeueAfter: requeueIntervalWhenDeploymentNotFound}, nil
+	appHealth, err := getApplicationHealth(ctx, r.Client, app)
+	if err != nil {
+		logger.Error(err, "unable to get the ApplicationHealth")
+		return ctrl.Result{}, err
+	}
+	// The progress deadline is evaluated even if the application has no deployment.
+	deploymentURLs := getDeploymentURLs(app, appHealth)
+	var requeue bool
+	if len(deploymentURLs) > 0 {
+		var resolutions []argocdcommenterv1.DeploymentResolution
+		var requeueToResolve bool
+		deploymentURLs, resolutions, requeueToResolve = resolveDeploymentURLs(ctx, r.Recorder, r.Notification, app, appHealth, deploymentURLs)
+		if err := recordDeploymentResolutions(ctx, r.Client, r.Scheme, app, resolutions); err != nil {
+			logger.Info("unable to record the deployment resolutions", "error", err)
 		}
-		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "DeploymentNotFoundRetryTimeout",
-			"deployment %s not found but retry timed out", deploymentURL)
-		return ctrl.Result{}, nil
+		deploymentURLs, requeue = filterDeploymentURLsToNotify(ctx, r.Recorder, r.Notification, app, appHealth, deploymentURLs,
+			fmt.Sprintf("status %s", app.Status.Health.Status))
+		requeue = requeue || requeueToResolve
 	}
-	if deploymentIsAlreadyHealthy {
-		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentAlreadyHealthy",
-			"skip on status %s because deployment %s is already healthy", app.Status.Health.Status, deploymentURL)
+	progressDeadline := r.getProgressDeadline(app)
+	if len(deploymentURLs) == 0 && progressDeadline == 0 {
+		if requeue {
+			return ctrl.Result{RequeueAfter: requeueIntervalWhenDeploymentNotFound}, nil
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -120,12 +141,148 @@ func (r *ApplicationHealthDeploymentReconciler) Reconcile(ctx context.Context, r
 		logger.Info("unable to determine Argo CD URL", "error", err)
 	}
 
-	if err := r.Notification.CreateDeploymentStatusOnHealthChanged(ctx, app, argocdURL); err != nil {
-		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
-			"unable to create a deployment status on health status %s: %s", app.Status.Health.Status, err)
-	} else {
+	// If the application is still progressing, requeue until the progress deadline.
+	// The deadline does not apply to the revision which has already become healthy.
+	var requeueAfter time.Duration
+	if requeue {
+		requeueAfter = requeueIntervalWhenDeploymentNotFound
+	}
+	if progressDeadline > 0 && app.Status.Health.Status == health.HealthStatusProgressing &&
+		!hasBecomeHealthy(appHealth, getCurrentRevision(app)) {
+		remaining := time.Until(syncOperationFinishedAt.Add(progressDeadline))
+		if remaining <= 0 {
+			return r.reconcileProgressDeadlineExceeded(ctx, app, argocdURL, deploymentURLs, progressDeadline)
+		}
+		if requeueAfter == 0 || remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+	}
+
+	var states []argocdcommenterv1.DeploymentState
+	for _, deploymentURL := range deploymentURLs {
+		// A requeue does not change the health status.
+		// Skip the deployment which has already received a deployment status on the same health status.
+		if hs := findDeploymentHealthStatus(appHealth, deploymentURL, getCurrentRevision(app)); hs != "" && hs == app.Status.Health.Status {
+			continue
+		}
+		state, err := r.Notification.CreateDeploymentStatusOnHealthChanged(ctx, app, argocdURL, deploymentURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
+				"unable to create a deployment status to %s on health status %s: %s", deploymentURL, app.Status.Health.Status, err)
+			continue
+		}
 		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeploymentStatus",
-			"created a deployment status on health status %s", app.Status.Health.Status)
+			"created a deployment status to %s on health status %s", deploymentURL, app.Status.Health.Status)
+		if state == "success" {
+			// The superseded deployments are recorded before the current one, not to be trimmed first.
+			states = append(states, r.deactivateSupersededDeployments(ctx, app, appHealth, deploymentURL)...)
+		}
+		if state != "" {
+			states = append(states, argocdcommenterv1.DeploymentState{
+				URL:          deploymentURL,
+				State:        state,
+				Revision:     getCurrentRevision(app),
+				HealthStatus: string(app.Status.Health.Status),
+			})
+		}
+	}
+	if err := recordDeploymentStates(ctx, r.Client, r.Scheme, app, states); err != nil {
+		logger.Info("unable to record the deployment states", "error", err)
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// deactivateSupersededDeployments sets the previous deployments of the same environment to inactive.
+// It deactivates only the deployments recorded in the ApplicationHealth.
+// It returns the states of the deactivated deployments at their revisions.
+func (r *ApplicationHealthDeploymentReconciler) deactivateSupersededDeployments(ctx context.Context, app argocdv1alpha1.Application,
+	appHealth *argocdcommenterv1.ApplicationHealth, deploymentURL string) []argocdcommenterv1.DeploymentState {
+	superseded, err := r.Notification.DeactivateSupersededDeployments(ctx, app, deploymentURL, getOwnedDeploymentURLs(appHealth))
+	if err != nil {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "DeactivateDeploymentError",
+			"unable to deactivate the deployments superseded by %s: %s", deploymentURL, err)
+	}
+	var states []argocdcommenterv1.DeploymentState
+	for _, sd := range superseded {
+		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "DeactivatedDeployment",
+			"deactivated the deployment %s superseded by %s", sd.URL, deploymentURL)
+		states = append(states, argocdcommenterv1.DeploymentState{
+			URL:      sd.URL,
+			State:    "inactive",
+			Revision: sd.Revision,
+		})
+	}
+	return states
+}
+
+func (r *ApplicationHealthDeploymentReconciler) getProgressDeadline(app argocdv1alpha1.Application) time.Duration {
+	progressDeadline, err := argocd.GetProgressDeadline(app)
+	if err != nil {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "InvalidProgressDeadline",
+			"fallback to the default progress deadline: %s", err)
+		return r.DefaultProgressDeadline
+	}
+	if progressDeadline == 0 {
+		return r.DefaultProgressDeadline
+	}
+	return progressDeadline
+}
+
+// reconcileProgressDeadlineExceeded creates a failure deployment status and a comment once per revision.
+func (r *ApplicationHealthDeploymentReconciler) reconcileProgressDeadlineExceeded(ctx context.Context,
+	app argocdv1alpha1.Application, argocdURL string, deploymentURLs []string, progressDeadline time.Duration) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	sourceRevisions := argocd.GetSourceRevisions(app)
+	if len(sourceRevisions) == 0 {
+		return ctrl.Result{}, nil
+	}
+	currentRevision := sourceRevisions[0].Revision
+	appHealth, err := getOrCreateApplicationHealth(ctx, r.Client, r.Scheme, app)
+	if err != nil {
+		logger.Error(err, "unable to get the ApplicationHealth")
+		return ctrl.Result{}, err
+	}
+	if appHealth.Status.ProgressDeadlineExceededRevision == currentRevision {
+		return ctrl.Result{}, nil
+	}
+	r.Recorder.Eventf(&app, corev1.EventTypeWarning, "ProgressDeadlineExceeded",
+		"not healthy within the progress deadline %s at revision %s", progressDeadline, currentRevision)
+
+	var states []argocdcommenterv1.DeploymentState
+	for _, deploymentURL := range deploymentURLs {
+		state, err := r.Notification.CreateDeploymentStatusOnProgressDeadlineExceeded(ctx, app, argocdURL, deploymentURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
+				"unable to create a deployment status to %s on progress deadline exceeded: %s", deploymentURL, err)
+			continue
+		}
+		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeploymentStatus",
+			"created a deployment status to %s on progress deadline exceeded", deploymentURL)
+		if state != "" {
+			states = append(states, argocdcommenterv1.DeploymentState{
+				URL:          deploymentURL,
+				State:        state,
+				Revision:     getCurrentRevision(app),
+				HealthStatus: string(app.Status.Health.Status),
+			})
+		}
+	}
+	if err := recordDeploymentStates(ctx, r.Client, r.Scheme, app, states); err != nil {
+		logger.Info("unable to record the deployment states", "error", err)
+	}
+	if err := r.Notification.CreateCommentsOnProgressDeadlineExceeded(ctx, app, argocdURL, progressDeadline); err != nil {
+		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateCommentError",
+			"unable to create a comment on progress deadline exceeded: %s", err)
+	} else {
+		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedComment",
+			"created a comment on progress deadline exceeded")
+	}
+
+	patch := client.MergeFrom(appHealth.DeepCopy())
+	appHealth.Status.ProgressDeadlineExceededRevision = currentRevision
+	if err := r.Client.Status().Patch(ctx, appHealth, patch); err != nil {
+		logger.Error(err, "unable to patch progressDeadlineExceededRevision")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	return ctrl.Result{}, nil
 }
@@ -136,14 +293,23 @@ func (r *ApplicationHealthDeploymentReconciler) SetupWithManager(mgr ctrl.Manage
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("applicationHealthDeployment").
 		For(&argocdv1alpha1.Application{}).
-		WithEventFilter(eventfilter.ApplicationChanged(filterApplicationHealthStatusForDeploymentStatus)).
+		WithEventFilter(eventfilter.ApplicationChanged(func(appOld, appNew argocdv1alpha1.Application) bool {
+			if !hasDeployment(appNew) && !r.hasProgressDeadline(appNew) {
+				return false
+			}
+			return filterApplicationHealthStatusForDeploymentStatus(appOld, appNew)
+		})).
 		Complete(r)
 }
 
+// hasProgressDeadline returns true if the progress deadline is enabled for the Application.
+// An invalid annotation falls back to the default.
+func (r *ApplicationHealthDeploymentReconciler) hasProgressDeadline(app argocdv1alpha1.Application) bool {
+	progressDeadline, err := argocd.GetProgressDeadline(app)
+	return r.DefaultProgressDeadline > 0 || (err == nil && progressDeadline > 0)
+}
+
 func filterApplicationHealthStatusForDeploymentStatus(appOld, appNew argocdv1alpha1.Application) bool {
-	if argocd.GetDeploymentURL(appNew) == "" {
-		return false
-	}
 
 	// When the health status is changed
 	healthOld, healthNew := appOld.Status.Health.Status, appNew.Status.Health.Status
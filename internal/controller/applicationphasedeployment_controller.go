@@ -18,10 +18,11 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"slices"
-	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
 	"github.com/int128/argocd-commenter/internal/argocd"
 	"github.com/int128/argocd-commenter/internal/controller/eventfilter"
 	"github.com/int128/argocd-commenter/internal/notification"
@@ -45,6 +46,8 @@ type ApplicationPhaseDeploymentReconciler struct {
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;watch;list
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;watch;list
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=argocdcommenter.int128.github.io,resources=applicationhealths,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=argocdcommenter.int128.github.io,resources=applicationhealths/status,verbs=get;update;patch
 
 func (r *ApplicationPhaseDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -61,45 +64,151 @@ func (r *ApplicationPhaseDeploymentReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, nil
 	}
 
-	deploymentURL := argocd.GetDeploymentURL(app)
-	if deploymentURL == "" {
-		return ctrl.Result{}, nil
+	argocdURL, err := argocd.GetExternalURL(ctx, r.Client, req.Namespace)
+	if err != nil {
+		logger.Info("unable to determine Argo CD URL", "error", err)
+	}
+
+	appHealth, err := getApplicationHealth(ctx, r.Client, app)
+	if err != nil {
+		logger.Error(err, "unable to get the ApplicationHealth")
+		return ctrl.Result{}, err
 	}
-	deploymentIsAlreadyHealthy, err := r.Notification.CheckIfDeploymentIsAlreadyHealthy(ctx, deploymentURL)
-	if notification.IsNotFoundError(err) {
-		// Retry until the application is synced with a valid GitHub Deployment.
-		// https://github.com/int128/argocd-commenter/issues/762
-		lastOperationAt := argocd.GetLastOperationAt(app).Time
-		if time.Since(lastOperationAt) < requeueTimeoutWhenDeploymentNotFound {
-			r.Recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentNotFound",
-				"deployment %s not found, retry after %s", deploymentURL, requeueIntervalWhenDeploymentNotFound)
-			return ctrl.Result{RequeueAfter: requeueIntervalWhenDeploymentNotFound}, nil
+
+	// On rollback, the deployments in the annotations belong to the revision rolled back from.
+	if annotatedURLs := argocd.GetDeploymentURLs(app); len(annotatedURLs) > 0 {
+		rollback, err := r.Notification.IsRollback(ctx, app)
+		if err != nil {
+			logger.Error(err, "unable to determine if the sync operation is a rollback")
+			return ctrl.Result{}, err
+		}
+		if rollback {
+			r.createDeploymentStatusesOnRollback(ctx, app, argocdURL, findResolvedDeploymentURLs(app, appHealth, annotatedURLs, true))
+			return ctrl.Result{}, nil
 		}
-		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "DeploymentNotFoundRetryTimeout",
-			"deployment %s not found but retry timed out", deploymentURL)
-		return ctrl.Result{}, nil
 	}
-	if deploymentIsAlreadyHealthy {
-		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentAlreadyHealthy",
-			"skip on sync operation phase %s because deployment %s is already healthy", phase, deploymentURL)
+
+	deploymentURLs := getDeploymentURLs(app, appHealth)
+	// The deployment is usually created on Running.
+	// If it was not recorded, such as an error, it is created on the next phase.
+	if len(deploymentURLs) == 0 && argocd.GetDeploymentEnvironment(app) != "" {
+		deploymentURL, err := r.createDeployment(ctx, app, argocdURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentError",
+				"unable to create a deployment: %s", err)
+			return ctrl.Result{}, err
+		}
+		if deploymentURL != "" {
+			deploymentURLs = []string{deploymentURL}
+		}
+	}
+	if len(deploymentURLs) == 0 {
 		return ctrl.Result{}, nil
 	}
-
-	argocdURL, err := argocd.GetExternalURL(ctx, r.Client, req.Namespace)
-	if err != nil {
-		logger.Info("unable to determine Argo CD URL", "error", err)
+	deploymentURLs, resolutions, requeueToResolve := resolveDeploymentURLs(ctx, r.Recorder, r.Notification, app, appHealth, deploymentURLs)
+	if err := recordDeploymentResolutions(ctx, r.Client, r.Scheme, app, resolutions); err != nil {
+		logger.Info("unable to record the deployment resolutions", "error", err)
 	}
-
-	if err := r.Notification.CreateDeploymentStatusOnPhaseChanged(ctx, app, argocdURL); err != nil {
-		r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
-			"unable to create a deployment status on sync operation phase %s: %s", phase, err)
-	} else {
+	deploymentURLs, requeue := filterDeploymentURLsToNotify(ctx, r.Recorder, r.Notification, app, appHealth, deploymentURLs,
+		fmt.Sprintf("sync operation phase %s", phase))
+
+	var states []argocdcommenterv1.DeploymentState
+	for _, deploymentURL := range deploymentURLs {
+		state, err := r.Notification.CreateDeploymentStatusOnPhaseChanged(ctx, app, argocdURL, deploymentURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
+				"unable to create a deployment status to %s on sync operation phase %s: %s", deploymentURL, phase, err)
+			continue
+		}
 		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeploymentStatus",
-			"created a deployment status on sync operation phase %s", phase)
+			"created a deployment status to %s on sync operation phase %s", deploymentURL, phase)
+		if state != "" {
+			states = append(states, argocdcommenterv1.DeploymentState{
+				URL:      deploymentURL,
+				State:    state,
+				Revision: getCurrentRevision(app),
+			})
+		}
+	}
+	if err := recordDeploymentStates(ctx, r.Client, r.Scheme, app, states); err != nil {
+		logger.Info("unable to record the deployment states", "error", err)
+	}
+	if requeue || requeueToResolve {
+		return ctrl.Result{RequeueAfter: requeueIntervalWhenDeploymentNotFound}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
+// createDeploymentStatusesOnRollback creates the deployment statuses to the deployments of the revision rolled back from.
+// The states are recorded at the previous revision, because the deployments belong to it.
+func (r *ApplicationPhaseDeploymentReconciler) createDeploymentStatusesOnRollback(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, deploymentURLs []string) {
+	logger := log.FromContext(ctx)
+	previousRevision := argocd.GetSourceRevisions(app)[0].PreviousRevision
+	var states []argocdcommenterv1.DeploymentState
+	for _, deploymentURL := range deploymentURLs {
+		state, err := r.Notification.CreateDeploymentStatusOnRollback(ctx, app, argocdURL, deploymentURL)
+		if err != nil {
+			r.Recorder.Eventf(&app, corev1.EventTypeWarning, "CreateDeploymentStatusError",
+				"unable to create a deployment status to %s on rollback: %s", deploymentURL, err)
+			continue
+		}
+		r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeploymentStatus",
+			"created a deployment status to %s on rollback", deploymentURL)
+		if state != "" {
+			states = append(states, argocdcommenterv1.DeploymentState{
+				URL:      deploymentURL,
+				State:    state,
+				Revision: previousRevision,
+			})
+		}
+	}
+	if err := recordDeploymentStates(ctx, r.Client, r.Scheme, app, states); err != nil {
+		logger.Info("unable to record the deployment states", "error", err)
+	}
+}
+
+// createDeployment creates a deployment for the current revision,
+// and stores the URL into the ApplicationHealth.
+// On rollback, it updates the deployment of the revision rolled back from.
+// If the URL could not be stored, it returns an error to retry.
+// The retry finds the created deployment instead of creating another one.
+func (r *ApplicationPhaseDeploymentReconciler) createDeployment(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) (string, error) {
+	sourceRevisions := argocd.GetSourceRevisions(app)
+	if len(sourceRevisions) == 0 {
+		return "", nil
+	}
+	appHealth, err := getOrCreateApplicationHealth(ctx, r.Client, r.Scheme, app)
+	if err != nil {
+		return "", err
+	}
+	if appHealth.Status.DeploymentURL != "" && appHealth.Status.DeploymentRevision == sourceRevisions[0].PreviousRevision {
+		rollback, err := r.Notification.IsRollback(ctx, app)
+		if err != nil {
+			return "", err
+		}
+		if rollback {
+			r.createDeploymentStatusesOnRollback(ctx, app, argocdURL, []string{appHealth.Status.DeploymentURL})
+		}
+	}
+	deploymentURL, err := r.Notification.CreateDeployment(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	if deploymentURL == "" {
+		return "", nil
+	}
+	r.Recorder.Eventf(&app, corev1.EventTypeNormal, "CreatedDeployment",
+		"created a deployment %s", deploymentURL)
+
+	patch := client.MergeFrom(appHealth.DeepCopy())
+	appHealth.Status.DeploymentURL = deploymentURL
+	appHealth.Status.DeploymentRevision = sourceRevisions[0].Revision
+	if err := r.Client.Status().Patch(ctx, appHealth, patch); err != nil {
+		return "", fmt.Errorf("unable to patch the deployment URL: %w", err)
+	}
+	return deploymentURL, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApplicationPhaseDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Recorder = mgr.GetEventRecorderFor("application-phase-deployment")
@@ -111,7 +220,7 @@ func (r *ApplicationPhaseDeploymentReconciler) SetupWithManager(mgr ctrl.Manager
 }
 
 func filterApplicationSyncOperationPhaseForDeploymentStatus(appOld, appNew argocdv1alpha1.Application) bool {
-	if argocd.GetDeploymentURL(appNew) == "" {
+	if !hasDeployment(appNew) {
 		return false
 	}
 
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-github/v80/github"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Progress deadline", func() {
+	var comments *githubmock.Comments
+	var createDeploymentStatus *githubmock.CreateDeploymentStatus
+
+	BeforeEach(func() {
+		By("Setting up the comment and deployment status endpoints")
+		comments = &githubmock.Comments{}
+		createDeploymentStatus = &githubmock.CreateDeploymentStatus{}
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-progress-deadline/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301/pulls",
+			githubmock.ListPullRequestsWithCommit(301),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-progress-deadline/pulls/301/files",
+			githubmock.ListPullRequestFiles(),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-progress-deadline/issues/301/comments?per_page=100",
+			comments,
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-progress-deadline/issues/301/comments",
+			&githubmock.CreateComment{Store: comments},
+		)
+		githubServer.Handle(
+			"PATCH /api/v3/repos/owner/repo-progress-deadline/issues/comments/1",
+			&githubmock.EditComment{Store: comments, ID: 1},
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-progress-deadline/deployments/301/statuses",
+			&githubmock.ListDeploymentStatus{Response: []*github.DeploymentStatus{}},
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-progress-deadline/deployments/301/statuses",
+			createDeploymentStatus,
+		)
+	})
+
+	newApplication := func(annotations map[string]string) argocdv1alpha1.Application {
+		annotations["argocd-commenter.int128.github.io/progress-deadline"] = "3s"
+		return argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "fixture-progress-deadline-",
+				Namespace:    "default",
+				Annotations:  annotations,
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-progress-deadline.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+	}
+
+	syncAndKeepProgressing := func(ctx context.Context, app *argocdv1alpha1.Application) {
+		By("Updating the application to succeeded")
+		startedAt := metav1.Now()
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, app)).Should(Succeed())
+
+		By("Updating the application to progressing")
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusProgressing,
+		}
+		Expect(k8sClient.Update(ctx, app)).Should(Succeed())
+	}
+
+	It("Should create a failure deployment status once after the deadline", func(ctx context.Context) {
+		app := newApplication(map[string]string{
+			"argocd-commenter.int128.github.io/deployment-url": "https://api.github.com/repos/owner/repo-progress-deadline/deployments/301",
+		})
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		syncAndKeepProgressing(ctx, &app)
+
+		By("Waiting for the in_progress statuses on the phase and health status")
+		Eventually(func() int { return createDeploymentStatus.Count() }).Should(Equal(2))
+
+		By("Waiting for the progress deadline")
+		Eventually(func() int { return createDeploymentStatus.Count() }, "5s").Should(Equal(3))
+		Eventually(func() []string { return comments.Bodies() }).Should(
+			ContainElement(ContainSubstring("did not become healthy within 3s")))
+		Consistently(func() int { return createDeploymentStatus.Count() }, "1s").Should(Equal(3))
+	}, SpecTimeout(10*time.Second))
+
+	It("Should create a comment even if the application has no deployment", func(ctx context.Context) {
+		app := newApplication(map[string]string{})
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		syncAndKeepProgressing(ctx, &app)
+
+		By("Waiting for the progress deadline")
+		Eventually(func() []string { return comments.Bodies() }, "5s").Should(
+			ContainElement(ContainSubstring("did not become healthy within 3s")))
+		Expect(createDeploymentStatus.Count()).Should(BeZero())
+	}, SpecTimeout(10*time.Second))
+
+	It("Should not fail the revision which has already become healthy", func(ctx context.Context) {
+		app := newApplication(map[string]string{
+			"argocd-commenter.int128.github.io/deployment-url": "https://api.github.com/repos/owner/repo-progress-deadline/deployments/301",
+		})
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+
+		By("Updating the application to succeeded and healthy after the deadline")
+		startedAt := metav1.NewTime(time.Now().Add(-10 * time.Second))
+		finishedAt := metav1.NewTime(time.Now().Add(-10 * time.Second))
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func(g Gomega) {
+			var appHealth argocdcommenterv1.ApplicationHealth
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&app), &appHealth)).Should(Succeed())
+			g.Expect(appHealth.Status.LastHealthyRevision).Should(Equal("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301"))
+			g.Expect(appHealth.Status.DeploymentStates).Should(ContainElement(argocdcommenterv1.DeploymentState{
+				URL:          "https://api.github.com/repos/owner/repo-progress-deadline/deployments/301",
+				State:        "success",
+				Revision:     "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa301",
+				HealthStatus: "Healthy",
+			}))
+		}).Should(Succeed())
+		healthyCount := createDeploymentStatus.Count()
+
+		By("Updating the application to progressing by a rollout restart")
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusProgressing,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Consistently(func() int { return createDeploymentStatus.Count() }, "1s").Should(Equal(healthyCount))
+		Expect(comments.Bodies()).ShouldNot(ContainElement(ContainSubstring("did not become healthy")))
+	}, SpecTimeout(10*time.Second))
+})
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	"github.com/int128/argocd-commenter/internal/notification"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Summary comment", func() {
+	var app argocdv1alpha1.Application
+	var comments *githubmock.Comments
+
+	BeforeEach(func(ctx context.Context) {
+		By("Starting the reconciler")
+		startManager(ctx, func(mgr ctrl.Manager, nc notification.Client) error {
+			return (&ApplicationSummaryCommentReconciler{
+				Client:       mgr.GetClient(),
+				Scheme:       mgr.GetScheme(),
+				Notification: nc,
+			}).SetupWithManager(mgr)
+		})
+
+		By("Setting up a comment endpoint")
+		comments = &githubmock.Comments{}
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-summary-comment/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa304/pulls",
+			githubmock.ListPullRequestsWithCommit(304),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-summary-comment/pulls/304/files",
+			githubmock.ListPullRequestFiles(),
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-summary-comment/issues/304/comments?per_page=100",
+			comments,
+		)
+		githubServer.Handle(
+			"POST /api/v3/repos/owner/repo-summary-comment/issues/304/comments",
+			&githubmock.CreateComment{Store: comments},
+		)
+		// The application comment may be created before the summary comment.
+		for _, id := range []int64{1, 2} {
+			githubServer.Handle(
+				fmt.Sprintf("PATCH /api/v3/repos/owner/repo-summary-comment/issues/comments/%d", id),
+				&githubmock.EditComment{Store: comments, ID: id},
+			)
+		}
+
+		By("Creating an application")
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fixture-summary-comment",
+				Namespace: "default",
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-summary-comment.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+		DeferCleanup(func(ctx context.Context) {
+			Expect(k8sClient.Delete(ctx, &app)).Should(Succeed())
+		})
+	})
+
+	It("Should update a single summary comment on each transition", func(ctx context.Context) {
+		By("Updating the application to running")
+		startedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:     synccommon.OperationRunning,
+			StartedAt: startedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa304",
+				},
+			},
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() []string { return findSummaryComments(comments) }).Should(ConsistOf(
+			ContainSubstring("Running"),
+		))
+
+		By("Updating the application to succeeded")
+		finishedAt := metav1.Now()
+		app.Status.OperationState = &argocdv1alpha1.OperationState{
+			Phase:      synccommon.OperationSucceeded,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Operation: argocdv1alpha1.Operation{
+				Sync: &argocdv1alpha1.SyncOperation{
+					Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa304",
+				},
+			},
+		}
+		app.Status.Health = argocdv1alpha1.AppHealthStatus{
+			Status: health.HealthStatusHealthy,
+		}
+		Expect(k8sClient.Update(ctx, &app)).Should(Succeed())
+		Eventually(func() []string { return findSummaryComments(comments) }).Should(ConsistOf(
+			SatisfyAll(
+				ContainSubstring("fixture-summary-comment"),
+				ContainSubstring("Succeeded"),
+				ContainSubstring("Healthy"),
+			),
+		))
+	}, SpecTimeout(3*time.Second))
+})
+
+// findSummaryComments returns the summary comments,
+// excluding the application comments posted by the other reconcilers.
+func findSummaryComments(comments *githubmock.Comments) []string {
+	var summaries []string
+	for _, body := range comments.Bodies() {
+		if strings.Contains(body, "<!-- argocd-commenter:summary -->") {
+			summaries = append(summaries, body)
+		}
+	}
+	return summaries
+}
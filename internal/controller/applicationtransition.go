@@ -0,0 +1,54 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/int128/argocd-commenter/internal/argocd"
+)
+
+// filterApplicationTransition returns true if the sync operation phase or the health status is changed.
+// It is shared by the reconcilers which reflect both of them, such as a check run or summary comment.
+func filterApplicationTransition(appOld, appNew argocdv1alpha1.Application) bool {
+	phaseOld, phaseNew := argocd.GetSyncOperationPhase(appOld), argocd.GetSyncOperationPhase(appNew)
+	if phaseOld != phaseNew && phaseNew != "" {
+		return true
+	}
+	healthOld, healthNew := appOld.Status.Health.Status, appNew.Status.Health.Status
+	return healthOld != healthNew
+}
+
+// getRequeueTimeToEvaluateHealthStatus returns the time to wait before evaluating the health status,
+// or zero if it can be evaluated now.
+// The health status may be stale just after the sync operation.
+// https://github.com/int128/argocd-commenter/issues/1044
+func getRequeueTimeToEvaluateHealthStatus(app argocdv1alpha1.Application) time.Duration {
+	if argocd.GetSyncOperationPhase(app) != synccommon.OperationSucceeded {
+		return 0
+	}
+	syncOperationFinishedAt := argocd.GetSyncOperationFinishedAt(app)
+	if syncOperationFinishedAt == nil {
+		return 0
+	}
+	if time.Since(syncOperationFinishedAt.Time) < requeueTimeToEvaluateHealthStatusAfterSyncOperation {
+		return requeueTimeToEvaluateHealthStatusAfterSyncOperation
+	}
+	return 0
+}
@@ -0,0 +1,356 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/notification"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hasDeployment returns true if the Application has a deployment URL in the annotations,
+// or the controller creates a deployment for the environment in the annotation.
+func hasDeployment(app argocdv1alpha1.Application) bool {
+	return len(argocd.GetDeploymentURLs(app)) > 0 || argocd.GetDeploymentEnvironment(app) != ""
+}
+
+// getDeploymentURLs returns the deployment URLs in the annotations.
+// If the annotations are not set, it returns the URL of the deployment created by the controller
+// for the current revision, or nil if it has not been created yet.
+func getDeploymentURLs(app argocdv1alpha1.Application, appHealth *argocdcommenterv1.ApplicationHealth) []string {
+	if deploymentURLs := argocd.GetDeploymentURLs(app); len(deploymentURLs) > 0 {
+		return deploymentURLs
+	}
+	if argocd.GetDeploymentEnvironment(app) == "" || appHealth == nil {
+		return nil
+	}
+	if appHealth.Status.DeploymentURL == "" || appHealth.Status.DeploymentRevision != getCurrentRevision(app) {
+		return nil
+	}
+	return []string{appHealth.Status.DeploymentURL}
+}
+
+// getCurrentRevision returns the revision of the first source, or empty string if not synced.
+func getCurrentRevision(app argocdv1alpha1.Application) string {
+	sourceRevisions := argocd.GetSourceRevisions(app)
+	if len(sourceRevisions) == 0 {
+		return ""
+	}
+	return sourceRevisions[0].Revision
+}
+
+// filterDeploymentURLsToNotify returns the deployment URLs which should receive a deployment status.
+// It skips a deployment which is already healthy.
+// It determines the state from the ApplicationHealth if recorded at the current revision,
+// otherwise it calls GitHub API.
+// If a deployment is not found, it returns requeue to retry
+// until the application is synced with a valid GitHub Deployment.
+// https://github.com/int128/argocd-commenter/issues/762
+func filterDeploymentURLsToNotify(ctx context.Context, recorder record.EventRecorder, nc notification.Client,
+	app argocdv1alpha1.Application, appHealth *argocdcommenterv1.ApplicationHealth, deploymentURLs []string, on string) (targets []string, requeue bool) {
+	currentRevision := getCurrentRevision(app)
+	for _, deploymentURL := range deploymentURLs {
+		var deploymentIsAlreadyHealthy bool
+		if state := findDeploymentState(appHealth, deploymentURL, currentRevision); state != "" {
+			deploymentIsAlreadyHealthy = state == "success"
+		} else {
+			var err error
+			deploymentIsAlreadyHealthy, err = nc.CheckIfDeploymentIsAlreadyHealthy(ctx, deploymentURL)
+			if notification.IsNotFoundError(err) {
+				if retryIfDeploymentNotFound(recorder, app, deploymentURL) {
+					requeue = true
+				}
+				continue
+			}
+		}
+		if deploymentIsAlreadyHealthy {
+			recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentAlreadyHealthy",
+				"skip on %s because deployment %s is already healthy", on, deploymentURL)
+			continue
+		}
+		targets = append(targets, deploymentURL)
+	}
+	return targets, requeue
+}
+
+// retryIfDeploymentNotFound records an event and returns true
+// if it should retry until the application is synced with a valid GitHub Deployment.
+func retryIfDeploymentNotFound(recorder record.EventRecorder, app argocdv1alpha1.Application, deploymentURL string) bool {
+	lastOperationAt := argocd.GetLastOperationAt(app).Time
+	if time.Since(lastOperationAt) < requeueTimeoutWhenDeploymentNotFound {
+		recorder.Eventf(&app, corev1.EventTypeNormal, "DeploymentNotFound",
+			"deployment %s not found, retry after %s", deploymentURL, requeueIntervalWhenDeploymentNotFound)
+		return true
+	}
+	recorder.Eventf(&app, corev1.EventTypeWarning, "DeploymentNotFoundRetryTimeout",
+		"deployment %s not found but retry timed out", deploymentURL)
+	return false
+}
+
+// resolveDeploymentURLs resolves the deployment queries to the deployment URLs.
+// A query is resolved at the current revision of the source in the repository of the query.
+// A query without the source in the repository is skipped with an event.
+// It looks up the ApplicationHealth first, and calls GitHub API if not resolved yet.
+// It returns the new resolutions to record.
+// If a deployment is not found, it returns requeue to retry until the deployment is created.
+func resolveDeploymentURLs(ctx context.Context, recorder record.EventRecorder, nc notification.Client,
+	app argocdv1alpha1.Application, appHealth *argocdcommenterv1.ApplicationHealth, deploymentURLs []string,
+) (resolvedURLs []string, resolutions []argocdcommenterv1.DeploymentResolution, requeue bool) {
+	for _, deploymentURL := range deploymentURLs {
+		if !notification.IsDeploymentQuery(deploymentURL) {
+			resolvedURLs = append(resolvedURLs, deploymentURL)
+			continue
+		}
+		sourceRevision := notification.GetDeploymentQuerySourceRevision(app, deploymentURL)
+		if sourceRevision == nil {
+			if getCurrentRevision(app) != "" {
+				recorder.Eventf(&app, corev1.EventTypeWarning, "DeploymentQuerySourceNotFound",
+					"no source of the repository in the deployment query %s", deploymentURL)
+			}
+			continue
+		}
+		if resolvedURL := findDeploymentResolution(appHealth, deploymentURL, sourceRevision.Revision); resolvedURL != "" {
+			resolvedURLs = append(resolvedURLs, resolvedURL)
+			continue
+		}
+		resolvedURL, err := nc.ResolveDeploymentURL(ctx, deploymentURL, sourceRevision.Revision)
+		if err != nil {
+			recorder.Eventf(&app, corev1.EventTypeWarning, "ResolveDeploymentError",
+				"unable to resolve the deployment %s: %s", deploymentURL, err)
+			continue
+		}
+		if resolvedURL == "" {
+			if retryIfDeploymentNotFound(recorder, app, deploymentURL) {
+				requeue = true
+			}
+			continue
+		}
+		recorder.Eventf(&app, corev1.EventTypeNormal, "ResolvedDeployment",
+			"resolved the deployment %s to %s", deploymentURL, resolvedURL)
+		resolvedURLs = append(resolvedURLs, resolvedURL)
+		resolutions = append(resolutions, argocdcommenterv1.DeploymentResolution{
+			Query:    deploymentURL,
+			Revision: sourceRevision.Revision,
+			URL:      resolvedURL,
+		})
+	}
+	return resolvedURLs, resolutions, requeue
+}
+
+// findResolvedDeploymentURLs resolves the deployment queries only from the ApplicationHealth.
+// A query is resolved at the current revision of the source in the repository of the query,
+// or the previous revision if atPreviousRevision is true.
+// A query not resolved yet is excluded.
+func findResolvedDeploymentURLs(app argocdv1alpha1.Application, appHealth *argocdcommenterv1.ApplicationHealth, deploymentURLs []string, atPreviousRevision bool) []string {
+	var resolvedURLs []string
+	for _, deploymentURL := range deploymentURLs {
+		if !notification.IsDeploymentQuery(deploymentURL) {
+			resolvedURLs = append(resolvedURLs, deploymentURL)
+			continue
+		}
+		sourceRevision := notification.GetDeploymentQuerySourceRevision(app, deploymentURL)
+		if sourceRevision == nil {
+			continue
+		}
+		revision := sourceRevision.Revision
+		if atPreviousRevision {
+			revision = sourceRevision.PreviousRevision
+		}
+		if resolvedURL := findDeploymentResolution(appHealth, deploymentURL, revision); resolvedURL != "" {
+			resolvedURLs = append(resolvedURLs, resolvedURL)
+		}
+	}
+	return resolvedURLs
+}
+
+// maxDeploymentResolutions is the maximum number of the deployment resolutions in the ApplicationHealth.
+// The oldest one is removed when exceeded.
+const maxDeploymentResolutions = 10
+
+// findDeploymentResolution returns the deployment URL resolved from the query at the revision,
+// or empty string if not resolved yet.
+func findDeploymentResolution(appHealth *argocdcommenterv1.ApplicationHealth, deploymentQuery, revision string) string {
+	if appHealth == nil {
+		return ""
+	}
+	for _, dr := range appHealth.Status.DeploymentResolutions {
+		if dr.Query == deploymentQuery && dr.Revision == revision {
+			return dr.URL
+		}
+	}
+	return ""
+}
+
+// recordDeploymentResolutions stores the deployment resolutions into the ApplicationHealth.
+// It keeps the resolutions of the previous revisions for rollback.
+// A merge patch replaces the whole list, so it patches with the optimistic lock
+// and retries on conflict not to drop the entries written by the other reconcilers.
+func recordDeploymentResolutions(ctx context.Context, c client.Client, scheme *runtime.Scheme, app argocdv1alpha1.Application, resolutions []argocdcommenterv1.DeploymentResolution) error {
+	if len(resolutions) == 0 {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		appHealth, err := getOrCreateApplicationHealth(ctx, c, scheme, app)
+		if err != nil {
+			return err
+		}
+		patch := client.MergeFromWithOptions(appHealth.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		for _, resolution := range resolutions {
+			appHealth.Status.DeploymentResolutions = slices.DeleteFunc(appHealth.Status.DeploymentResolutions,
+				func(dr argocdcommenterv1.DeploymentResolution) bool {
+					return dr.Query == resolution.Query && dr.Revision == resolution.Revision
+				})
+			appHealth.Status.DeploymentResolutions = append(appHealth.Status.DeploymentResolutions, resolution)
+		}
+		if n := len(appHealth.Status.DeploymentResolutions); n > maxDeploymentResolutions {
+			appHealth.Status.DeploymentResolutions = appHealth.Status.DeploymentResolutions[n-maxDeploymentResolutions:]
+		}
+		if err := c.Status().Patch(ctx, appHealth, patch); err != nil {
+			return fmt.Errorf("unable to patch the deployment resolutions: %w", err)
+		}
+		return nil
+	})
+}
+
+// maxDeploymentStates is the maximum number of the deployment states in the ApplicationHealth.
+// The oldest one is removed when exceeded.
+const maxDeploymentStates = 10
+
+// findDeploymentState returns the last state of the deployment status created at the revision,
+// or empty string if not recorded.
+func findDeploymentState(appHealth *argocdcommenterv1.ApplicationHealth, deploymentURL, revision string) string {
+	if appHealth == nil {
+		return ""
+	}
+	for _, ds := range appHealth.Status.DeploymentStates {
+		if ds.URL == deploymentURL && ds.Revision == revision {
+			return ds.State
+		}
+	}
+	return ""
+}
+
+// findDeploymentHealthStatus returns the health status on which the last deployment status is created at the revision,
+// or empty string if not recorded or created on a sync operation phase.
+func findDeploymentHealthStatus(appHealth *argocdcommenterv1.ApplicationHealth, deploymentURL, revision string) health.HealthStatusCode {
+	if appHealth == nil {
+		return ""
+	}
+	for _, ds := range appHealth.Status.DeploymentStates {
+		if ds.URL == deploymentURL && ds.Revision == revision {
+			return health.HealthStatusCode(ds.HealthStatus)
+		}
+	}
+	return ""
+}
+
+// hasBecomeHealthy returns true if the revision has already become healthy,
+// that is, the health comment reconciler has recorded it or a deployment has received a success status.
+// An application may be progressing again after healthy, such as a rollout restart or scaling.
+func hasBecomeHealthy(appHealth *argocdcommenterv1.ApplicationHealth, revision string) bool {
+	if appHealth == nil {
+		return false
+	}
+	if appHealth.Status.LastHealthyRevision == revision {
+		return true
+	}
+	return slices.ContainsFunc(appHealth.Status.DeploymentStates, func(ds argocdcommenterv1.DeploymentState) bool {
+		return ds.Revision == revision && ds.State == "success"
+	})
+}
+
+// getOwnedDeploymentURLs returns the deployment URLs which the Application has deployed,
+// that is, recorded in the ApplicationHealth.
+func getOwnedDeploymentURLs(appHealth *argocdcommenterv1.ApplicationHealth) []string {
+	if appHealth == nil {
+		return nil
+	}
+	var deploymentURLs []string
+	if appHealth.Status.DeploymentURL != "" {
+		deploymentURLs = append(deploymentURLs, appHealth.Status.DeploymentURL)
+	}
+	for _, ds := range appHealth.Status.DeploymentStates {
+		deploymentURLs = append(deploymentURLs, ds.URL)
+	}
+	for _, dr := range appHealth.Status.DeploymentResolutions {
+		deploymentURLs = append(deploymentURLs, dr.URL)
+	}
+	return deploymentURLs
+}
+
+// recordDeploymentStates stores the states of the created deployment statuses into the ApplicationHealth.
+// It keeps the last state of each deployment.
+// It patches with the optimistic lock and retries on conflict, as well as recordDeploymentResolutions.
+func recordDeploymentStates(ctx context.Context, c client.Client, scheme *runtime.Scheme, app argocdv1alpha1.Application, states []argocdcommenterv1.DeploymentState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		appHealth, err := getOrCreateApplicationHealth(ctx, c, scheme, app)
+		if err != nil {
+			return err
+		}
+		patch := client.MergeFromWithOptions(appHealth.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		for _, state := range states {
+			appHealth.Status.DeploymentStates = slices.DeleteFunc(appHealth.Status.DeploymentStates,
+				func(ds argocdcommenterv1.DeploymentState) bool { return ds.URL == state.URL })
+			appHealth.Status.DeploymentStates = append(appHealth.Status.DeploymentStates, state)
+		}
+		if n := len(appHealth.Status.DeploymentStates); n > maxDeploymentStates {
+			appHealth.Status.DeploymentStates = appHealth.Status.DeploymentStates[n-maxDeploymentStates:]
+		}
+		if err := c.Status().Patch(ctx, appHealth, patch); err != nil {
+			return fmt.Errorf("unable to patch the deployment states: %w", err)
+		}
+		return nil
+	})
+}
+
+// getApplicationHealth returns the ApplicationHealth of the Application, or nil if not found.
+func getApplicationHealth(ctx context.Context, c client.Reader, app argocdv1alpha1.Application) (*argocdcommenterv1.ApplicationHealth, error) {
+	var appHealth argocdcommenterv1.ApplicationHealth
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&app), &appHealth); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get the ApplicationHealth: %w", err)
+	}
+	return &appHealth, nil
+}
+
+// getOrCreateApplicationHealth returns the ApplicationHealth of the Application.
+// If it does not exist, it creates one owned by the Application.
+func getOrCreateApplicationHealth(ctx context.Context, c client.Client, scheme *runtime.Scheme, app argocdv1alpha1.Application) (*argocdcommenterv1.ApplicationHealth, error) {
+	var appHealth argocdcommenterv1.ApplicationHealth
+	err := c.Get(ctx, client.ObjectKeyFromObject(&app), &appHealth)
+	if err == nil {
+		return &appHealth, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get the ApplicationHealth: %w", err)
+	}
+	appHealth.ObjectMeta = metav1.ObjectMeta{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+	}
+	if err := ctrl.SetControllerReference(&app, &appHealth, scheme); err != nil {
+		return nil, fmt.Errorf("unable to set the controller reference to the ApplicationHealth: %w", err)
+	}
+	if err := c.Create(ctx, &appHealth); err != nil {
+		return nil, fmt.Errorf("unable to create an ApplicationHealth: %w", err)
+	}
+	return &appHealth, nil
+}
@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-github/v80/github"
+	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	"github.com/int128/argocd-commenter/internal/notification"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("getDeploymentURLs", func() {
+	newApp := func(annotations map[string]string) argocdv1alpha1.Application {
+		return argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Source: &argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/owner/repo.git"},
+			},
+			Status: argocdv1alpha1.ApplicationStatus{
+				OperationState: &argocdv1alpha1.OperationState{
+					Operation: argocdv1alpha1.Operation{
+						Sync: &argocdv1alpha1.SyncOperation{Revision: "sha2"},
+					},
+				},
+			},
+		}
+	}
+	newAppHealth := func(deploymentRevision string) *argocdcommenterv1.ApplicationHealth {
+		return &argocdcommenterv1.ApplicationHealth{
+			Status: argocdcommenterv1.ApplicationHealthStatus{
+				DeploymentURL:      "https://api.github.com/repos/owner/repo/deployments/2",
+				DeploymentRevision: deploymentRevision,
+			},
+		}
+	}
+
+	DescribeTable("returns the deployment URLs",
+		func(app argocdv1alpha1.Application, appHealth *argocdcommenterv1.ApplicationHealth, want []string) {
+			Expect(getDeploymentURLs(app, appHealth)).Should(Equal(want))
+		},
+		Entry("annotated URL takes precedence",
+			newApp(map[string]string{
+				"argocd-commenter.int128.github.io/deployment-url":         "https://api.github.com/repos/owner/repo/deployments/1",
+				"argocd-commenter.int128.github.io/deployment-environment": "production",
+			}),
+			newAppHealth("sha2"),
+			[]string{"https://api.github.com/repos/owner/repo/deployments/1"},
+		),
+		Entry("created deployment at the current revision",
+			newApp(map[string]string{"argocd-commenter.int128.github.io/deployment-environment": "production"}),
+			newAppHealth("sha2"),
+			[]string{"https://api.github.com/repos/owner/repo/deployments/2"},
+		),
+		Entry("created deployment at a previous revision",
+			newApp(map[string]string{"argocd-commenter.int128.github.io/deployment-environment": "production"}),
+			newAppHealth("sha1"),
+			nil,
+		),
+		Entry("no ApplicationHealth",
+			newApp(map[string]string{"argocd-commenter.int128.github.io/deployment-environment": "production"}),
+			nil,
+			nil,
+		),
+		Entry("no annotation",
+			newApp(nil),
+			newAppHealth("sha2"),
+			nil,
+		),
+	)
+})
+
+var _ = Describe("findDeploymentState", func() {
+	appHealth := &argocdcommenterv1.ApplicationHealth{
+		Status: argocdcommenterv1.ApplicationHealthStatus{
+			DeploymentStates: []argocdcommenterv1.DeploymentState{
+				{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "inactive", Revision: "sha1"},
+				{URL: "https://api.github.com/repos/owner/repo/deployments/2", State: "success", Revision: "sha2"},
+			},
+		},
+	}
+
+	DescribeTable("returns the state",
+		func(appHealth *argocdcommenterv1.ApplicationHealth, deploymentURL, revision, want string) {
+			Expect(findDeploymentState(appHealth, deploymentURL, revision)).Should(Equal(want))
+		},
+		Entry("recorded", appHealth, "https://api.github.com/repos/owner/repo/deployments/2", "sha2", "success"),
+		Entry("recorded at another revision", appHealth, "https://api.github.com/repos/owner/repo/deployments/1", "sha2", ""),
+		Entry("not recorded", appHealth, "https://api.github.com/repos/owner/repo/deployments/3", "sha2", ""),
+		Entry("no ApplicationHealth", nil, "https://api.github.com/repos/owner/repo/deployments/2", "sha2", ""),
+	)
+})
+
+var _ = Describe("recordDeploymentStates", func() {
+	var app argocdv1alpha1.Application
+
+	BeforeEach(func(ctx context.Context) {
+		app = argocdv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "fixture-deployment-states-",
+				Namespace:    "default",
+			},
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Project: "default",
+				Source: &argocdv1alpha1.ApplicationSource{
+					RepoURL:        "https://github.com/owner/repo-deployment-states.git",
+					Path:           "test",
+					TargetRevision: "main",
+				},
+				Destination: argocdv1alpha1.ApplicationDestination{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "default",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &app)).Should(Succeed())
+	})
+
+	getDeploymentStates := func(ctx context.Context) []argocdcommenterv1.DeploymentState {
+		var appHealth argocdcommenterv1.ApplicationHealth
+		Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(&app), &appHealth)).Should(Succeed())
+		return appHealth.Status.DeploymentStates
+	}
+
+	It("Should replace the state of the same deployment", func(ctx context.Context) {
+		Expect(recordDeploymentStates(ctx, k8sClient, scheme.Scheme, app, []argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "in_progress", Revision: "sha1"},
+			{URL: "https://api.github.com/repos/owner/repo/deployments/2", State: "in_progress", Revision: "sha1"},
+		})).Should(Succeed())
+		Expect(recordDeploymentStates(ctx, k8sClient, scheme.Scheme, app, []argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "success", Revision: "sha1", HealthStatus: "Healthy"},
+		})).Should(Succeed())
+		Expect(getDeploymentStates(ctx)).Should(Equal([]argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/2", State: "in_progress", Revision: "sha1"},
+			{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "success", Revision: "sha1", HealthStatus: "Healthy"},
+		}))
+	})
+
+	It("Should keep the latest states up to the limit", func(ctx context.Context) {
+		var want []argocdcommenterv1.DeploymentState
+		for i := range maxDeploymentStates + 2 {
+			state := argocdcommenterv1.DeploymentState{
+				URL:      fmt.Sprintf("https://api.github.com/repos/owner/repo/deployments/%d", i),
+				State:    "success",
+				Revision: fmt.Sprintf("sha%d", i),
+			}
+			Expect(recordDeploymentStates(ctx, k8sClient, scheme.Scheme, app,
+				[]argocdcommenterv1.DeploymentState{state})).Should(Succeed())
+			want = append(want, state)
+		}
+		Expect(getDeploymentStates(ctx)).Should(Equal(want[2:]))
+	})
+
+	It("Should keep the states written by another reconciler after a stale read", func(ctx context.Context) {
+		Expect(recordDeploymentStates(ctx, k8sClient, scheme.Scheme, app, []argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "in_progress", Revision: "sha1"},
+		})).Should(Succeed())
+		var stale argocdcommenterv1.ApplicationHealth
+		Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(&app), &stale)).Should(Succeed())
+
+		By("Recording a state by another reconciler")
+		Expect(recordDeploymentStates(ctx, k8sClient, scheme.Scheme, app, []argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/2", State: "in_progress", Revision: "sha1"},
+		})).Should(Succeed())
+
+		By("Recording a state from the stale cache")
+		c := &staleApplicationHealthClient{Client: k8sClient, stale: &stale}
+		Expect(recordDeploymentStates(ctx, c, scheme.Scheme, app, []argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/3", State: "in_progress", Revision: "sha1"},
+		})).Should(Succeed())
+		Expect(getDeploymentStates(ctx)).Should(Equal([]argocdcommenterv1.DeploymentState{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/1", State: "in_progress", Revision: "sha1"},
+			{URL: "https://api.github.com/repos/owner/repo/deployments/2", State: "in_progress", Revision: "sha1"},
+			{URL: "https://api.github.com/repos/owner/repo/deployments/3", State: "in_progress", Revision: "sha1"},
+		}))
+	})
+})
+
+// staleApplicationHealthClient returns the stale ApplicationHealth on the first read,
+// as the informer cache does before catching up.
+type staleApplicationHealthClient struct {
+	crclient.Client
+	stale *argocdcommenterv1.ApplicationHealth
+}
+
+func (c *staleApplicationHealthClient) Get(ctx context.Context, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+	if appHealth, ok := obj.(*argocdcommenterv1.ApplicationHealth); ok && c.stale != nil {
+		c.stale.DeepCopyInto(appHealth)
+		c.stale = nil
+		return nil
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+var _ = Describe("resolveDeploymentURLs", func() {
+	const query = "repo=owner/repo-resolve-app,environment=pr-123"
+	app := argocdv1alpha1.Application{
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Sources: argocdv1alpha1.ApplicationSources{
+				{RepoURL: "https://github.com/owner/repo-resolve-manifests.git"},
+				{RepoURL: "https://github.com/owner/repo-resolve-app.git"},
+			},
+		},
+		Status: argocdv1alpha1.ApplicationStatus{
+			OperationState: &argocdv1alpha1.OperationState{
+				StartedAt: metav1.Now(),
+				Operation: argocdv1alpha1.Operation{
+					Sync: &argocdv1alpha1.SyncOperation{Revisions: []string{"sha1", "sha2"}},
+				},
+			},
+		},
+	}
+
+	It("Should resolve the query at the revision of the source in the repository", func(ctx context.Context) {
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-resolve-app/deployments?environment=pr-123&per_page=1&sha=sha2",
+			&githubmock.RecordRequests{Response: []*github.Deployment{{URL: github.Ptr("https://api.github.com/repos/owner/repo-resolve-app/deployments/2")}}},
+		)
+		nc := notification.NewClient(ghc, nil, nil)
+		resolvedURLs, resolutions, requeue := resolveDeploymentURLs(ctx, record.NewFakeRecorder(10), nc, app, nil,
+			[]string{query, "https://api.github.com/repos/owner/repo-resolve-app/deployments/1"})
+		Expect(resolvedURLs).Should(Equal([]string{
+			"https://api.github.com/repos/owner/repo-resolve-app/deployments/2",
+			"https://api.github.com/repos/owner/repo-resolve-app/deployments/1",
+		}))
+		Expect(resolutions).Should(Equal([]argocdcommenterv1.DeploymentResolution{
+			{Query: query, Revision: "sha2", URL: "https://api.github.com/repos/owner/repo-resolve-app/deployments/2"},
+		}))
+		Expect(requeue).Should(BeFalse())
+	})
+
+	It("Should use the resolution in the ApplicationHealth", func(ctx context.Context) {
+		appHealth := &argocdcommenterv1.ApplicationHealth{
+			Status: argocdcommenterv1.ApplicationHealthStatus{
+				DeploymentResolutions: []argocdcommenterv1.DeploymentResolution{
+					{Query: query, Revision: "sha2", URL: "https://api.github.com/repos/owner/repo-resolve-app/deployments/3"},
+				},
+			},
+		}
+		nc := notification.NewClient(ghc, nil, nil)
+		resolvedURLs, resolutions, requeue := resolveDeploymentURLs(ctx, record.NewFakeRecorder(10), nc, app, appHealth, []string{query})
+		Expect(resolvedURLs).Should(Equal([]string{"https://api.github.com/repos/owner/repo-resolve-app/deployments/3"}))
+		Expect(resolutions).Should(BeEmpty())
+		Expect(requeue).Should(BeFalse())
+	})
+
+	It("Should requeue if the deployment is not found", func(ctx context.Context) {
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-resolve-app/deployments?environment=pr-123&per_page=1&sha=sha2",
+			&githubmock.RecordRequests{Response: []*github.Deployment{}},
+		)
+		githubServer.Handle(
+			"GET /api/v3/repos/owner/repo-resolve-app/deployments?environment=pr-123&per_page=1&ref=sha2",
+			&githubmock.RecordRequests{Response: []*github.Deployment{}},
+		)
+		nc := notification.NewClient(ghc, nil, nil)
+		resolvedURLs, resolutions, requeue := resolveDeploymentURLs(ctx, record.NewFakeRecorder(10), nc, app, nil, []string{query})
+		Expect(resolvedURLs).Should(BeEmpty())
+		Expect(resolutions).Should(BeEmpty())
+		Expect(requeue).Should(BeTrue())
+	})
+})
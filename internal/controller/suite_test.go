@@ -26,19 +26,22 @@ import (
 	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
-	"github.com/int128/argocd-commenter/internal/controller/githubmock"
 	"github.com/int128/argocd-commenter/internal/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
 	"github.com/int128/argocd-commenter/internal/notification"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	argocdcommenterv1 "github.com/int128/argocd-commenter/api/v1"
 	// +kubebuilder:scaffold:imports
@@ -48,8 +51,10 @@ import (
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
 var (
+	cfg          *rest.Config
 	k8sClient    client.Client
 	githubServer githubmock.Server
+	ghc          github.Client
 )
 
 var _ = BeforeEach(func() {
@@ -98,7 +103,7 @@ var _ = BeforeSuite(func() {
 	}
 
 	// cfg is defined in this file globally.
-	cfg, err := testEnv.Start()
+	cfg, err = testEnv.Start()
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
@@ -134,15 +139,15 @@ var _ = BeforeSuite(func() {
 	})
 	GinkgoT().Setenv("GITHUB_TOKEN", "dummy-github-token")
 	GinkgoT().Setenv("GITHUB_ENTERPRISE_URL", githubMockServer.URL)
-	ghc, err := github.NewClient(ctx)
+	ghc, err = github.NewClient(ctx)
 	Expect(err).NotTo(HaveOccurred())
-	nc := notification.NewClient(ghc)
 
 	By("Setting up the controller manager")
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme.Scheme,
 	})
 	Expect(err).ToNot(HaveOccurred())
+	nc := notification.NewClient(ghc, notification.ConfigMapTemplateLoader{Client: k8sManager.GetClient()}, nil)
 
 	err = (&ApplicationPhaseCommentReconciler{
 		Client:       k8sManager.GetClient(),
@@ -187,6 +192,28 @@ var _ = BeforeSuite(func() {
 	}()
 })
 
+// startManager starts a controller manager with the reconciler set up by the function, until the spec is finished.
+// It is used for the reconcilers behind a feature flag, so that they do not interfere with the other specs.
+func startManager(ctx context.Context, setup func(mgr ctrl.Manager, nc notification.Client) error) {
+	skipNameValidation := true
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:     scheme.Scheme,
+		Metrics:    metricsserver.Options{BindAddress: "0"},
+		Controller: config.Controller{SkipNameValidation: &skipNameValidation},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	nc := notification.NewClient(ghc, notification.ConfigMapTemplateLoader{Client: mgr.GetClient()}, nil)
+	Expect(setup(mgr, nc)).Should(Succeed())
+
+	mgrCtx, cancel := context.WithCancel(context.TODO())
+	DeferCleanup(cancel)
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(mgrCtx)).Should(Succeed())
+	}()
+	Expect(mgr.GetCache().WaitForCacheSync(ctx)).Should(BeTrue())
+}
+
 // getFirstFoundEnvTestBinaryDir locates the first binary in the specified path.
 // ENVTEST-based tests depend on specific binaries, usually located in paths set by
 // controller-runtime. When running tests directly (e.g., via an IDE) without using
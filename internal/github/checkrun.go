@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// maxCheckRunAnnotations is the maximum number of annotations in a request.
+const maxCheckRunAnnotations = 50
+
+type CheckRun struct {
+	Name    string
+	HeadSHA string
+	// Status is one of queued, in_progress or completed.
+	Status string
+	// Conclusion is required if the status is completed.
+	Conclusion string
+	DetailsURL string
+	Title      string
+	Summary    string
+	// Text must be within 65535 characters.
+	Text string
+	// Annotations are shown in the files of the pull request.
+	Annotations []CheckRunAnnotation
+}
+
+type CheckRunAnnotation struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	// Level is one of notice, warning or failure.
+	Level   string
+	Title   string
+	Message string
+}
+
+// CreateOrUpdateCheckRun creates a check run, or updates the latest check run of the same name on the commit.
+// If the latest check run is completed and the new one is not, it creates a new check run.
+// GitHub appends the annotations on every update,
+// so they are sent only when a check run is created or the conclusion is changed.
+// Check Runs API is available only for GitHub App.
+// https://docs.github.com/en/rest/checks/runs
+func (c *client) CreateOrUpdateCheckRun(ctx context.Context, r Repository, cr CheckRun) error {
+	existing, err := c.findLatestCheckRun(ctx, r, cr.HeadSHA, cr.Name)
+	if err != nil {
+		return err
+	}
+	create := existing == nil || (existing.GetStatus() == "completed" && cr.Status != "completed")
+	output := &github.CheckRunOutput{
+		Title:   github.Ptr(cr.Title),
+		Summary: github.Ptr(cr.Summary),
+	}
+	if cr.Text != "" {
+		output.Text = github.Ptr(cr.Text)
+	}
+	if create || existing.GetConclusion() != cr.Conclusion {
+		for i, a := range cr.Annotations {
+			if i >= maxCheckRunAnnotations {
+				break
+			}
+			output.Annotations = append(output.Annotations, &github.CheckRunAnnotation{
+				Path:            github.Ptr(a.Path),
+				StartLine:       github.Ptr(a.StartLine),
+				EndLine:         github.Ptr(a.EndLine),
+				AnnotationLevel: github.Ptr(a.Level),
+				Title:           github.Ptr(a.Title),
+				Message:         github.Ptr(a.Message),
+			})
+		}
+	}
+	var conclusion *string
+	if cr.Conclusion != "" {
+		conclusion = github.Ptr(cr.Conclusion)
+	}
+
+	if create {
+		_, _, err := c.rest.Checks.CreateCheckRun(ctx, r.Owner, r.Name, github.CreateCheckRunOptions{
+			Name:       cr.Name,
+			HeadSHA:    cr.HeadSHA,
+			DetailsURL: github.Ptr(cr.DetailsURL),
+			Status:     github.Ptr(cr.Status),
+			Conclusion: conclusion,
+			Output:     output,
+		})
+		if err != nil {
+			return fmt.Errorf("could not create a check run %s on %s: %w", cr.Name, cr.HeadSHA, err)
+		}
+		return nil
+	}
+	_, _, err = c.rest.Checks.UpdateCheckRun(ctx, r.Owner, r.Name, existing.GetID(), github.UpdateCheckRunOptions{
+		Name:       cr.Name,
+		DetailsURL: github.Ptr(cr.DetailsURL),
+		Status:     github.Ptr(cr.Status),
+		Conclusion: conclusion,
+		Output:     output,
+	})
+	if err != nil {
+		return fmt.Errorf("could not update the check run %d: %w", existing.GetID(), err)
+	}
+	return nil
+}
+
+// findLatestCheckRun returns the latest check run of the name on the commit, or nil if not found.
+// It bypasses the HTTP cache to avoid creating a duplicated check run.
+func (c *client) findLatestCheckRun(ctx context.Context, r Repository, sha, name string) (*github.CheckRun, error) {
+	u := fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?check_name=%s&filter=latest",
+		r.Owner, r.Name, sha, url.QueryEscape(name))
+	req, err := c.rest.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a request: %w", err)
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	var result github.ListCheckRunsResults
+	if _, err := c.rest.Do(ctx, req, &result); err != nil {
+		return nil, fmt.Errorf("could not list check runs of %s: %w", sha, err)
+	}
+	if len(result.CheckRuns) == 0 {
+		return nil, nil
+	}
+	return result.CheckRuns[0], nil
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+// newMockClient returns a client connected to the mock server.
+func newMockClient(t *testing.T, sv *githubmock.Server) Client {
+	t.Helper()
+	s := httptest.NewServer(sv)
+	t.Cleanup(s.Close)
+	t.Setenv("GITHUB_TOKEN", "dummy-github-token")
+	t.Setenv("GITHUB_ENTERPRISE_URL", s.URL)
+	ghc, err := NewClient(context.TODO())
+	if err != nil {
+		t.Fatalf("NewClient error: %s", err)
+	}
+	return ghc
+}
+
+// respondJSON returns a handler which responds the value as JSON.
+func respondJSON(t *testing.T, v any) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("could not encode the response: %s", err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// CodeOwners represents the rules of a CODEOWNERS file.
+// https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners
+type CodeOwners []CodeOwnersRule
+
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// codeOwnersPaths are the locations of CODEOWNERS file in the order of precedence.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// GetCodeOwners returns the CODEOWNERS of the ref.
+// It returns nil if the repository has no CODEOWNERS file.
+func (c *client) GetCodeOwners(ctx context.Context, r Repository, ref string) (CodeOwners, error) {
+	for _, p := range codeOwnersPaths {
+		content, err := c.GetFileContent(ctx, r, ref, p)
+		if IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ParseCodeOwners(content), nil
+	}
+	return nil, nil
+}
+
+// ParseCodeOwners parses the content of CODEOWNERS file.
+func ParseCodeOwners(content string) CodeOwners {
+	var rules CodeOwners
+	for line := range strings.Lines(content) {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rules = append(rules, CodeOwnersRule{Pattern: fields[0], Owners: fields[1:]})
+	}
+	return rules
+}
+
+// Find returns the owners of the file.
+// The last matching rule takes precedence, as well as GitHub.
+func (co CodeOwners) Find(file string) []string {
+	for i := len(co) - 1; i >= 0; i-- {
+		if matchCodeOwnersPattern(co[i].Pattern, file) {
+			return co[i].Owners
+		}
+	}
+	return nil
+}
+
+// matchCodeOwnersPattern returns true if the file matches the pattern of gitignore syntax.
+// A "**" in the middle of the pattern is treated as "*".
+func matchCodeOwnersPattern(pattern, file string) bool {
+	segments := strings.Split(strings.Trim(file, "/"), "/")
+	if pattern == "*" || pattern == "**" || pattern == "/**" {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		pattern = rest
+	}
+	dirOnly := false
+	if rest, ok := strings.CutSuffix(pattern, "/**"); ok {
+		pattern, dirOnly = rest, true
+	}
+	if rest, ok := strings.CutSuffix(pattern, "/"); ok {
+		pattern, dirOnly = rest, true
+	}
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	patternSegments := len(strings.Split(pattern, "/"))
+
+	for start := range segments {
+		if anchored && start > 0 {
+			break
+		}
+		end := start + patternSegments
+		if end > len(segments) {
+			break
+		}
+		matched, err := path.Match(pattern, strings.Join(segments[start:end], "/"))
+		if err != nil || !matched {
+			continue
+		}
+		// If the pattern matches a directory, it matches all files under it.
+		if end < len(segments) || !dirOnly {
+			return true
+		}
+	}
+	return false
+}
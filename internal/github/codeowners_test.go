@@ -0,0 +1,51 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	got := ParseCodeOwners(`# comment
+*       @org/everyone
+
+/applications/app1/ @org/team1 @alice # inline comment
+*.yaml  user@example.com
+`)
+	want := CodeOwners{
+		{Pattern: "*", Owners: []string{"@org/everyone"}},
+		{Pattern: "/applications/app1/", Owners: []string{"@org/team1", "@alice"}},
+		{Pattern: "*.yaml", Owners: []string{"user@example.com"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCodeOwners_Find(t *testing.T) {
+	co := CodeOwners{
+		{Pattern: "*", Owners: []string{"@org/everyone"}},
+		{Pattern: "/applications/app1/", Owners: []string{"@org/team1"}},
+		{Pattern: "app2/", Owners: []string{"@org/team2"}},
+		{Pattern: "docs/*.md", Owners: []string{"@org/docs"}},
+		{Pattern: "**/secret.yaml", Owners: []string{"@org/security"}},
+	}
+	for file, want := range map[string][]string{
+		"README.md":                           {"@org/everyone"},
+		"applications/app1/deployment.yaml":   {"@org/team1"},
+		"other/applications/app1/deploy.yaml": {"@org/everyone"},
+		"applications/app2/deployment.yaml":   {"@org/team2"},
+		"app2":                                {"@org/everyone"},
+		"docs/index.md":                       {"@org/docs"},
+		"docs/sub/index.md":                   {"@org/everyone"},
+		"applications/app1/secret.yaml":       {"@org/security"},
+	} {
+		t.Run(file, func(t *testing.T) {
+			got := co.Find(file)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/v80/github"
 )
@@ -13,6 +14,13 @@ type Comment struct {
 	Body       string
 }
 
+type IssueComment struct {
+	ID          int64
+	NodeID      string
+	Body        string
+	IsMinimized bool
+}
+
 func (c *client) CreatePullRequestComment(ctx context.Context, r Repository, pullNumber int, body string) error {
 	_, _, err := c.rest.Issues.CreateComment(ctx, r.Owner, r.Name, pullNumber,
 		&github.IssueComment{Body: github.Ptr(body)})
@@ -22,6 +30,122 @@ func (c *client) CreatePullRequestComment(ctx context.Context, r Repository, pul
 	return nil
 }
 
+// ListPullRequestComments returns all comments of the pull request in ascending order of creation.
+// It bypasses the HTTP cache, because the caller edits a comment based on the latest body.
+func (c *client) ListPullRequestComments(ctx context.Context, r Repository, pullNumber int) ([]IssueComment, error) {
+	var comments []IssueComment
+	page := 1
+	for page != 0 {
+		u := fmt.Sprintf("repos/%s/%s/issues/%d/comments?per_page=100", r.Owner, r.Name, pullNumber)
+		if page > 1 {
+			u = fmt.Sprintf("%s&page=%d", u, page)
+		}
+		req, err := c.rest.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not create a request: %w", err)
+		}
+		req.Header.Set("Cache-Control", "no-cache")
+		var ghComments []*github.IssueComment
+		resp, err := c.rest.Do(ctx, req, &ghComments)
+		if err != nil {
+			return nil, fmt.Errorf("could not list comments of the pull request #%d: %w", pullNumber, err)
+		}
+		for _, ghComment := range ghComments {
+			comments = append(comments, IssueComment{
+				ID:     ghComment.GetID(),
+				NodeID: ghComment.GetNodeID(),
+				Body:   ghComment.GetBody(),
+			})
+		}
+		page = resp.NextPage
+	}
+	return comments, nil
+}
+
+func (c *client) EditPullRequestComment(ctx context.Context, r Repository, commentID int64, body string) error {
+	_, _, err := c.rest.Issues.EditComment(ctx, r.Owner, r.Name, commentID,
+		&github.IssueComment{Body: github.Ptr(body)})
+	if err != nil {
+		return fmt.Errorf("could not edit the comment %d: %w", commentID, err)
+	}
+	return nil
+}
+
+const listAuthoredCommentsQuery = `query ($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      comments(last: 100) {
+        nodes {
+          id
+          databaseId
+          body
+          isMinimized
+          viewerDidAuthor
+        }
+      }
+    }
+  }
+}`
+
+type listAuthoredCommentsData struct {
+	Repository struct {
+		PullRequest struct {
+			Comments struct {
+				Nodes []struct {
+					ID              string `json:"id"`
+					DatabaseID      int64  `json:"databaseId"`
+					Body            string `json:"body"`
+					IsMinimized     bool   `json:"isMinimized"`
+					ViewerDidAuthor bool   `json:"viewerDidAuthor"`
+				} `json:"nodes"`
+			} `json:"comments"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// ListAuthoredPullRequestComments returns the recent comments of the pull request
+// created by the current user or app, in ascending order of creation.
+// It looks up only the last 100 comments without pagination,
+// because an older comment is likely to be hidden already.
+// https://docs.github.com/en/graphql/reference/objects#issuecomment
+func (c *client) ListAuthoredPullRequestComments(ctx context.Context, r Repository, pullNumber int) ([]IssueComment, error) {
+	var data listAuthoredCommentsData
+	variables := map[string]any{"owner": r.Owner, "name": r.Name, "number": pullNumber}
+	if err := c.queryGraphQL(ctx, listAuthoredCommentsQuery, variables, &data); err != nil {
+		return nil, fmt.Errorf("could not list comments of the pull request #%d: %w", pullNumber, err)
+	}
+	var comments []IssueComment
+	for _, node := range data.Repository.PullRequest.Comments.Nodes {
+		if !node.ViewerDidAuthor {
+			continue
+		}
+		comments = append(comments, IssueComment{
+			ID:          node.DatabaseID,
+			NodeID:      node.ID,
+			Body:        node.Body,
+			IsMinimized: node.IsMinimized,
+		})
+	}
+	return comments, nil
+}
+
+const minimizeCommentMutation = `mutation ($subjectId: ID!) {
+  minimizeComment(input: {subjectId: $subjectId, classifier: OUTDATED}) {
+    minimizedComment {
+      isMinimized
+    }
+  }
+}`
+
+// MinimizeOutdatedComment hides the comment as outdated using GraphQL API.
+// https://docs.github.com/en/graphql/reference/mutations#minimizecomment
+func (c *client) MinimizeOutdatedComment(ctx context.Context, nodeID string) error {
+	if err := c.queryGraphQL(ctx, minimizeCommentMutation, map[string]any{"subjectId": nodeID}, nil); err != nil {
+		return fmt.Errorf("could not minimize the comment %s: %w", nodeID, err)
+	}
+	return nil
+}
+
 func (c *client) CreateCommitComment(ctx context.Context, r Repository, sha, body string) error {
 	_, _, err := c.rest.Repositories.CreateComment(ctx, r.Owner, r.Name, sha,
 		&github.RepositoryComment{Body: github.Ptr(body)})
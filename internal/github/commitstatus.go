@@ -0,0 +1,35 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+)
+
+type CommitStatus struct {
+	Context string
+	// State is one of error, failure, pending or success.
+	State       string
+	Description string
+	TargetURL   string
+}
+
+// CreateCommitStatus creates a commit status on the commit.
+// GitHub shows the latest status of each context.
+// https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+func (c *client) CreateCommitStatus(ctx context.Context, r Repository, sha string, cs CommitStatus) error {
+	status := github.RepoStatus{
+		Context:     github.Ptr(cs.Context),
+		State:       github.Ptr(cs.State),
+		Description: github.Ptr(cs.Description),
+	}
+	if cs.TargetURL != "" {
+		status.TargetURL = github.Ptr(cs.TargetURL)
+	}
+	_, _, err := c.rest.Repositories.CreateStatus(ctx, r.Owner, r.Name, sha, status)
+	if err != nil {
+		return fmt.Errorf("could not create a commit status %s on %s: %w", cs.Context, sha, err)
+	}
+	return nil
+}
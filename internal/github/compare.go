@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// maxCommitsInComparison is the maximum number of commits to find the associated pull requests.
+// Each commit requires an API call, so this should be reasonable to avoid the rate limit of GitHub API.
+const maxCommitsInComparison = 50
+
+const compareCommitsPerPage = 100
+
+// Status of a comparison, which describes the head revision relative to the base revision.
+const (
+	ComparisonStatusAhead     = "ahead"
+	ComparisonStatusBehind    = "behind"
+	ComparisonStatusIdentical = "identical"
+	ComparisonStatusDiverged  = "diverged"
+)
+
+type Comparison struct {
+	BaseRevision string
+	HeadRevision string
+	HTMLURL      string
+	// Status is one of ComparisonStatus.
+	Status       string
+	TotalCommits int
+	// CommitSHAs are the latest commits up to maxCommitsInComparison in chronological order.
+	CommitSHAs []string
+}
+
+// CompareRevisions compares the base and head revisions.
+// It fetches only the pages containing the latest commits.
+// https://docs.github.com/en/rest/commits/commits#compare-two-commits
+func (c *client) CompareRevisions(ctx context.Context, r Repository, base, head string) (*Comparison, error) {
+	comparison, _, err := c.rest.Repositories.CompareCommits(ctx, r.Owner, r.Name, base, head,
+		&github.ListOptions{PerPage: compareCommitsPerPage})
+	if err != nil {
+		return nil, fmt.Errorf("could not compare %s...%s: %w", base, head, err)
+	}
+	commits := comparison.Commits
+	totalCommits := comparison.GetTotalCommits()
+	if totalCommits > compareCommitsPerPage {
+		lastPage := (totalCommits + compareCommitsPerPage - 1) / compareCommitsPerPage
+		firstPage := (totalCommits-maxCommitsInComparison)/compareCommitsPerPage + 1
+		if firstPage > 1 {
+			commits = nil
+		}
+		for page := max(firstPage, 2); page <= lastPage; page++ {
+			pageComparison, _, err := c.rest.Repositories.CompareCommits(ctx, r.Owner, r.Name, base, head,
+				&github.ListOptions{PerPage: compareCommitsPerPage, Page: page})
+			if err != nil {
+				return nil, fmt.Errorf("could not compare %s...%s: %w", base, head, err)
+			}
+			commits = append(commits, pageComparison.Commits...)
+		}
+	}
+	var commitSHAs []string
+	for _, commit := range commits[max(len(commits)-maxCommitsInComparison, 0):] {
+		commitSHAs = append(commitSHAs, commit.GetSHA())
+	}
+	return &Comparison{
+		BaseRevision: base,
+		HeadRevision: head,
+		HTMLURL:      comparison.GetHTMLURL(),
+		Status:       comparison.GetStatus(),
+		TotalCommits: totalCommits,
+		CommitSHAs:   commitSHAs,
+	}, nil
+}
+
+// GetComparisonStatus returns the status of the head revision relative to the base revision.
+// This does not need the commits, so it fetches only the first commit.
+func (c *client) GetComparisonStatus(ctx context.Context, r Repository, base, head string) (string, error) {
+	comparison, _, err := c.rest.Repositories.CompareCommits(ctx, r.Owner, r.Name, base, head, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return "", fmt.Errorf("could not compare %s...%s: %w", base, head, err)
+	}
+	return comparison.GetStatus(), nil
+}
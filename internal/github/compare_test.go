@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+func newCommits(from, to int) []*github.RepositoryCommit {
+	var commits []*github.RepositoryCommit
+	for i := from; i < to; i++ {
+		commits = append(commits, &github.RepositoryCommit{SHA: github.Ptr(fmt.Sprintf("sha%d", i))})
+	}
+	return commits
+}
+
+func TestCompareRevisions(t *testing.T) {
+	t.Run("single page", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/base...head?per_page=100", respondJSON(t, github.CommitsComparison{
+			HTMLURL:      github.Ptr("https://github.com/owner/repo/compare/base...head"),
+			TotalCommits: github.Ptr(3),
+			Commits:      newCommits(0, 3),
+		}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.CompareRevisions(context.TODO(), Repository{Owner: "owner", Name: "repo"}, "base", "head")
+		if err != nil {
+			t.Fatalf("CompareRevisions error: %s", err)
+		}
+		want := &Comparison{
+			BaseRevision: "base",
+			HeadRevision: "head",
+			HTMLURL:      "https://github.com/owner/repo/compare/base...head",
+			TotalCommits: 3,
+			CommitSHAs:   []string{"sha0", "sha1", "sha2"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("latest commits of multiple pages", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/base...head?per_page=100", respondJSON(t, github.CommitsComparison{
+			TotalCommits: github.Ptr(220),
+			Commits:      newCommits(0, 100),
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/base...head?page=2&per_page=100", respondJSON(t, github.CommitsComparison{
+			TotalCommits: github.Ptr(220),
+			Commits:      newCommits(100, 200),
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/base...head?page=3&per_page=100", respondJSON(t, github.CommitsComparison{
+			TotalCommits: github.Ptr(220),
+			Commits:      newCommits(200, 220),
+		}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.CompareRevisions(context.TODO(), Repository{Owner: "owner", Name: "repo"}, "base", "head")
+		if err != nil {
+			t.Fatalf("CompareRevisions error: %s", err)
+		}
+		if got.TotalCommits != 220 {
+			t.Errorf("TotalCommits wants 220 but was %d", got.TotalCommits)
+		}
+		var want []string
+		for i := 170; i < 220; i++ {
+			want = append(want, fmt.Sprintf("sha%d", i))
+		}
+		if diff := cmp.Diff(want, got.CommitSHAs); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestGetComparisonStatus(t *testing.T) {
+	var sv githubmock.Server
+	sv.Handle("GET /api/v3/repos/owner/repo/compare/base...head?per_page=1", respondJSON(t, github.CommitsComparison{
+		Status:       github.Ptr("behind"),
+		TotalCommits: github.Ptr(0),
+	}))
+	ghc := newMockClient(t, &sv)
+	got, err := ghc.GetComparisonStatus(context.TODO(), Repository{Owner: "owner", Name: "repo"}, "base", "head")
+	if err != nil {
+		t.Fatalf("GetComparisonStatus error: %s", err)
+	}
+	if got != ComparisonStatusBehind {
+		t.Errorf("status wants %s but was %s", ComparisonStatusBehind, got)
+	}
+}
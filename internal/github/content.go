@@ -0,0 +1,25 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// GetFileContent returns the content of the file at the ref.
+// It returns an error satisfying IsNotFoundError if the file does not exist.
+func (c *client) GetFileContent(ctx context.Context, r Repository, ref, path string) (string, error) {
+	file, _, _, err := c.rest.Repositories.GetContents(ctx, r.Owner, r.Name, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return "", fmt.Errorf("could not get the content of %s: %w", path, err)
+	}
+	if file == nil {
+		return "", fmt.Errorf("%s is not a file", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("could not decode the content of %s: %w", path, err)
+	}
+	return content, nil
+}
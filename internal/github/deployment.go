@@ -3,8 +3,11 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/google/go-github/v80/github"
 )
@@ -12,14 +15,27 @@ import (
 type Deployment struct {
 	Repository Repository
 	Id         int64
+	// URL and SHA are set only if the deployment is returned from GitHub API.
+	URL string
+	SHA string
 }
 
-var patternDeploymentURL = regexp.MustCompile(`^https://api\.github\.com/repos/(.+?)/(.+?)/deployments/(\d+)$`)
+var patternDeploymentPath = regexp.MustCompile(`^repos/(.+?)/(.+?)/deployments/(\d+)$`)
 
-// ParseDeploymentURL parses the URL.
+// ParseDeploymentURL parses the URL of GitHub or the GitHub Enterprise Server of the client.
 // For example, https://api.github.com/repos/int128/sandbox/deployments/422988781
-func ParseDeploymentURL(s string) *Deployment {
-	m := patternDeploymentURL.FindStringSubmatch(s)
+// or https://ghes.example.com/api/v3/repos/int128/sandbox/deployments/422988781
+func (c *client) ParseDeploymentURL(s string) *Deployment {
+	for _, baseURL := range []string{"https://api.github.com/", c.rest.BaseURL.String()} {
+		if p, ok := strings.CutPrefix(s, baseURL); ok {
+			return parseDeploymentPath(p)
+		}
+	}
+	return nil
+}
+
+func parseDeploymentPath(s string) *Deployment {
+	m := patternDeploymentPath.FindStringSubmatch(s)
 	if len(m) != 4 {
 		return nil
 	}
@@ -33,6 +49,151 @@ func ParseDeploymentURL(s string) *Deployment {
 	}
 }
 
+// DeploymentQuery represents a condition to find a deployment.
+type DeploymentQuery struct {
+	Repository  Repository
+	Environment string
+}
+
+// ParseDeploymentQuery parses the comma-separated key=value pairs.
+// It returns nil if the format is invalid.
+// For example, repo=owner/repo,environment=pr-123
+func ParseDeploymentQuery(s string) *DeploymentQuery {
+	var q DeploymentQuery
+	for pair := range strings.SplitSeq(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil
+		}
+		switch k {
+		case "repo":
+			owner, name, ok := strings.Cut(v, "/")
+			if !ok || owner == "" || name == "" {
+				return nil
+			}
+			q.Repository = Repository{Owner: owner, Name: name}
+		case "environment":
+			q.Environment = v
+		default:
+			return nil
+		}
+	}
+	if q.Repository.Owner == "" || q.Environment == "" {
+		return nil
+	}
+	return &q
+}
+
+// FindLatestDeploymentURL returns the URL of the newest deployment of the environment
+// whose sha or ref matches the revision.
+// It bypasses the HTTP cache, because the deployment may be created just before.
+// It returns empty string if not found.
+func (c *client) FindLatestDeploymentURL(ctx context.Context, q DeploymentQuery, revision string) (string, error) {
+	for _, key := range []string{"sha", "ref"} {
+		// The deployments are sorted by the newest first.
+		deployments, err := c.listDeploymentsWithoutCache(ctx, q.Repository, url.Values{
+			key:           {revision},
+			"environment": {q.Environment},
+			"per_page":    {"1"},
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(deployments) > 0 {
+			return deployments[0].GetURL(), nil
+		}
+	}
+	return "", nil
+}
+
+// ListPreviousDeployments returns the deployments of the same environment created before the deployment.
+// It returns at most limit deployments in the newest first order.
+func (c *client) ListPreviousDeployments(ctx context.Context, d Deployment, limit int) ([]Deployment, error) {
+	deployment, _, err := c.rest.Repositories.GetDeployment(ctx, d.Repository.Owner, d.Repository.Name, d.Id)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API error: %w", err)
+	}
+	deployments, _, err := c.rest.Repositories.ListDeployments(ctx, d.Repository.Owner, d.Repository.Name, &github.DeploymentsListOptions{
+		Environment: deployment.GetEnvironment(),
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API error: %w", err)
+	}
+	var previousDeployments []Deployment
+	for _, pd := range deployments {
+		if len(previousDeployments) >= limit {
+			break
+		}
+		if pd.GetID() >= d.Id {
+			continue
+		}
+		previousDeployments = append(previousDeployments, Deployment{
+			Repository: d.Repository,
+			Id:         pd.GetID(),
+			URL:        pd.GetURL(),
+			SHA:        pd.GetSHA(),
+		})
+	}
+	return previousDeployments, nil
+}
+
+type DeploymentRequest struct {
+	Ref                   string
+	Environment           string
+	Description           string
+	ProductionEnvironment bool
+	TransientEnvironment  bool
+}
+
+// CreateDeployment creates a deployment and returns the URL of it.
+// If a deployment of the environment already exists at the ref, it returns the URL of it instead,
+// so that a retry does not create a duplicated deployment.
+// It does not merge the default branch and skips the commit status checks,
+// because the revision has already been deployed by Argo CD.
+func (c *client) CreateDeployment(ctx context.Context, r Repository, dr DeploymentRequest) (string, error) {
+	deployments, err := c.listDeploymentsWithoutCache(ctx, r, url.Values{
+		"sha":         {dr.Ref},
+		"environment": {dr.Environment},
+		"per_page":    {"1"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(deployments) > 0 {
+		return deployments[0].GetURL(), nil
+	}
+	d, _, err := c.rest.Repositories.CreateDeployment(ctx, r.Owner, r.Name, &github.DeploymentRequest{
+		Ref:                   github.Ptr(dr.Ref),
+		Environment:           github.Ptr(dr.Environment),
+		Description:           github.Ptr(dr.Description),
+		AutoMerge:             github.Ptr(false),
+		RequiredContexts:      &[]string{},
+		ProductionEnvironment: github.Ptr(dr.ProductionEnvironment),
+		TransientEnvironment:  github.Ptr(dr.TransientEnvironment),
+	})
+	if err != nil {
+		return "", fmt.Errorf("GitHub API error: %w", err)
+	}
+	return d.GetURL(), nil
+}
+
+// listDeploymentsWithoutCache returns the deployments matching the query.
+// It bypasses the HTTP cache to find a deployment created just before.
+func (c *client) listDeploymentsWithoutCache(ctx context.Context, r Repository, query url.Values) ([]*github.Deployment, error) {
+	u := fmt.Sprintf("repos/%s/%s/deployments?%s", r.Owner, r.Name, query.Encode())
+	req, err := c.rest.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a request: %w", err)
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	var deployments []*github.Deployment
+	if _, err := c.rest.Do(ctx, req, &deployments); err != nil {
+		return nil, fmt.Errorf("GitHub API error: %w", err)
+	}
+	return deployments, nil
+}
+
 type DeploymentStatus struct {
 	State          string
 	Description    string
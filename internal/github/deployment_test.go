@@ -1,32 +1,219 @@
 package github
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
 
 func TestParseDeploymentURL(t *testing.T) {
-	t.Run("valid", func(t *testing.T) {
-		d := ParseDeploymentURL("https://api.github.com/repos/int128/sandbox/deployments/422988781")
-		if d == nil {
-			t.Fatalf("deployment was nil")
+	var sv githubmock.Server
+	s := httptest.NewServer(&sv)
+	t.Cleanup(s.Close)
+	t.Setenv("GITHUB_TOKEN", "dummy-github-token")
+	t.Setenv("GITHUB_ENTERPRISE_URL", s.URL)
+	ghc, err := NewClient(context.TODO())
+	if err != nil {
+		t.Fatalf("NewClient error: %s", err)
+	}
+	want := &Deployment{Repository: Repository{Owner: "int128", Name: "sandbox"}, Id: 422988781}
+
+	for s, want := range map[string]*Deployment{
+		"https://api.github.com/repos/int128/sandbox/deployments/422988781":  want,
+		s.URL + "/api/v3/repos/int128/sandbox/deployments/422988781":         want,
+		"https://ghes.example.com/api/v3/repos/int128/sandbox/deployments/1": nil,
+		"https://api.github.com/repos/int128/sandbox":                        nil,
+		"": nil,
+	} {
+		t.Run(s, func(t *testing.T) {
+			got := ghc.ParseDeploymentURL(s)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDeploymentQuery(t *testing.T) {
+	for s, want := range map[string]*DeploymentQuery{
+		"repo=owner/app,environment=pr-123": {
+			Repository:  Repository{Owner: "owner", Name: "app"},
+			Environment: "pr-123",
+		},
+		"environment=staging, repo=owner/app": {
+			Repository:  Repository{Owner: "owner", Name: "app"},
+			Environment: "staging",
+		},
+		"":                              nil,
+		"repo=owner/app":                nil,
+		"environment=pr-123":            nil,
+		"repo=owner,environment=pr-123": nil,
+		"repo=owner/app,environment=pr-123,ref=main":                        nil,
+		"https://api.github.com/repos/int128/sandbox/deployments/422988781": nil,
+	} {
+		t.Run(s, func(t *testing.T) {
+			got := ParseDeploymentQuery(s)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateDeployment(t *testing.T) {
+	dr := DeploymentRequest{Ref: "sha1", Environment: "production"}
+
+	t.Run("create", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=production&per_page=1&sha=sha1",
+			respondJSON(t, []*github.Deployment{}))
+		sv.Handle("POST /api/v3/repos/owner/repo/deployments", respondJSON(t, github.Deployment{
+			URL: github.Ptr("https://api.github.com/repos/owner/repo/deployments/2"),
+		}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.CreateDeployment(context.TODO(), Repository{Owner: "owner", Name: "repo"}, dr)
+		if err != nil {
+			t.Fatalf("CreateDeployment error: %s", err)
 		}
-		if want := (Repository{Owner: "int128", Name: "sandbox"}); d.Repository != want {
-			t.Errorf("want %+v but was %+v", want, d.Repository)
+		if want := "https://api.github.com/repos/owner/repo/deployments/2"; got != want {
+			t.Errorf("CreateDeployment wants %s but was %s", want, got)
 		}
-		if d.Id != 422988781 {
-			t.Errorf("want %d but was %d", 422988781, d.Id)
+	})
+
+	t.Run("bypass the cache", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=production&per_page=1&sha=sha1",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Cache-Control"); got != "no-cache" {
+					t.Errorf("Cache-Control wants no-cache but was %q", got)
+				}
+				respondJSON(t, []*github.Deployment{{URL: github.Ptr("https://api.github.com/repos/owner/repo/deployments/1")}})(w, r)
+			}))
+		ghc := newMockClient(t, &sv)
+		if _, err := ghc.CreateDeployment(context.TODO(), Repository{Owner: "owner", Name: "repo"}, dr); err != nil {
+			t.Fatalf("CreateDeployment error: %s", err)
 		}
 	})
 
-	t.Run("not deployment", func(t *testing.T) {
-		d := ParseDeploymentURL("https://api.github.com/repos/int128/sandbox")
-		if d != nil {
-			t.Errorf("want nil but was %+v", d)
+	t.Run("already exists", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=production&per_page=1&sha=sha1",
+			respondJSON(t, []*github.Deployment{{URL: github.Ptr("https://api.github.com/repos/owner/repo/deployments/1")}}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.CreateDeployment(context.TODO(), Repository{Owner: "owner", Name: "repo"}, dr)
+		if err != nil {
+			t.Fatalf("CreateDeployment error: %s", err)
+		}
+		if want := "https://api.github.com/repos/owner/repo/deployments/1"; got != want {
+			t.Errorf("CreateDeployment wants %s but was %s", want, got)
+		}
+	})
+}
+
+func TestFindLatestDeploymentURL(t *testing.T) {
+	q := DeploymentQuery{Repository: Repository{Owner: "owner", Name: "repo"}, Environment: "pr-123"}
+
+	t.Run("sha matches", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=pr-123&per_page=1&sha=sha1",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Cache-Control"); got != "no-cache" {
+					t.Errorf("Cache-Control wants no-cache but was %q", got)
+				}
+				respondJSON(t, []*github.Deployment{{URL: github.Ptr("https://api.github.com/repos/owner/repo/deployments/2")}})(w, r)
+			}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.FindLatestDeploymentURL(context.TODO(), q, "sha1")
+		if err != nil {
+			t.Fatalf("FindLatestDeploymentURL error: %s", err)
+		}
+		if want := "https://api.github.com/repos/owner/repo/deployments/2"; got != want {
+			t.Errorf("FindLatestDeploymentURL wants %s but was %s", want, got)
 		}
 	})
 
-	t.Run("empty", func(t *testing.T) {
-		d := ParseDeploymentURL("")
-		if d != nil {
-			t.Errorf("want nil but was %+v", d)
+	t.Run("ref matches", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=pr-123&per_page=1&sha=sha1",
+			respondJSON(t, []*github.Deployment{}))
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=pr-123&per_page=1&ref=sha1",
+			respondJSON(t, []*github.Deployment{{URL: github.Ptr("https://api.github.com/repos/owner/repo/deployments/3")}}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.FindLatestDeploymentURL(context.TODO(), q, "sha1")
+		if err != nil {
+			t.Fatalf("FindLatestDeploymentURL error: %s", err)
+		}
+		if want := "https://api.github.com/repos/owner/repo/deployments/3"; got != want {
+			t.Errorf("FindLatestDeploymentURL wants %s but was %s", want, got)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=pr-123&per_page=1&sha=sha1",
+			respondJSON(t, []*github.Deployment{}))
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=pr-123&per_page=1&ref=sha1",
+			respondJSON(t, []*github.Deployment{}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.FindLatestDeploymentURL(context.TODO(), q, "sha1")
+		if err != nil {
+			t.Fatalf("FindLatestDeploymentURL error: %s", err)
+		}
+		if got != "" {
+			t.Errorf("FindLatestDeploymentURL wants empty but was %s", got)
+		}
+	})
+}
+
+func TestListPreviousDeployments(t *testing.T) {
+	var sv githubmock.Server
+	sv.Handle("GET /api/v3/repos/owner/repo/deployments/5", respondJSON(t, github.Deployment{
+		ID:          github.Ptr(int64(5)),
+		Environment: github.Ptr("staging"),
+	}))
+	var deployments []*github.Deployment
+	for id := int64(6); id >= 1; id-- {
+		deployments = append(deployments, &github.Deployment{
+			ID:  github.Ptr(id),
+			URL: github.Ptr(fmt.Sprintf("https://api.github.com/repos/owner/repo/deployments/%d", id)),
+			SHA: github.Ptr(fmt.Sprintf("sha%d", id)),
+		})
+	}
+	sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=staging&per_page=100", respondJSON(t, deployments))
+	ghc := newMockClient(t, &sv)
+	repository := Repository{Owner: "owner", Name: "repo"}
+
+	t.Run("deployments before the deployment", func(t *testing.T) {
+		got, err := ghc.ListPreviousDeployments(context.TODO(), Deployment{Repository: repository, Id: 5}, 10)
+		if err != nil {
+			t.Fatalf("ListPreviousDeployments error: %s", err)
+		}
+		var gotIDs []int64
+		for _, d := range got {
+			gotIDs = append(gotIDs, d.Id)
+		}
+		if diff := cmp.Diff([]int64{4, 3, 2, 1}, gotIDs); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("up to the limit", func(t *testing.T) {
+		got, err := ghc.ListPreviousDeployments(context.TODO(), Deployment{Repository: repository, Id: 5}, 2)
+		if err != nil {
+			t.Fatalf("ListPreviousDeployments error: %s", err)
+		}
+		want := []Deployment{
+			{Repository: repository, Id: 4, URL: "https://api.github.com/repos/owner/repo/deployments/4", SHA: "sha4"},
+			{Repository: repository, Id: 3, URL: "https://api.github.com/repos/owner/repo/deployments/3", SHA: "sha3"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
 		}
 	})
 }
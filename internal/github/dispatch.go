@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// Dispatch represents a target of repository_dispatch or workflow_dispatch event.
+type Dispatch struct {
+	Repository Repository
+	// EventType of repository_dispatch event.
+	EventType string
+	// Workflow file name of workflow_dispatch event.
+	Workflow string
+	// Ref of workflow_dispatch event.
+	// If empty, the default branch is used.
+	Ref string
+}
+
+// ParseDispatch parses the comma-separated key=value pairs.
+// It returns nil if the format is invalid.
+// For example,
+// repo=owner/repo,event-type=smoke-test => repository_dispatch
+// repo=owner/repo,workflow=e2e.yaml,ref=main => workflow_dispatch
+func ParseDispatch(s string) *Dispatch {
+	var d Dispatch
+	for pair := range strings.SplitSeq(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil
+		}
+		switch k {
+		case "repo":
+			owner, name, ok := strings.Cut(v, "/")
+			if !ok || owner == "" || name == "" {
+				return nil
+			}
+			d.Repository = Repository{Owner: owner, Name: name}
+		case "event-type":
+			d.EventType = v
+		case "workflow":
+			d.Workflow = v
+		case "ref":
+			d.Ref = v
+		default:
+			return nil
+		}
+	}
+	if d.Repository.Owner == "" {
+		return nil
+	}
+	if (d.EventType == "") == (d.Workflow == "") {
+		return nil
+	}
+	return &d
+}
+
+type DispatchPayload struct {
+	Application    string   `json:"application"`
+	Namespace      string   `json:"namespace"`
+	Revisions      []string `json:"revisions"`
+	EnvironmentURL string   `json:"environment_url,omitempty"`
+	PullRequests   []int    `json:"pull_requests"`
+}
+
+// workflowInputs returns the inputs of workflow_dispatch event.
+// An input must be a string.
+func (p DispatchPayload) workflowInputs() map[string]any {
+	var pullRequests []string
+	for _, n := range p.PullRequests {
+		pullRequests = append(pullRequests, strconv.Itoa(n))
+	}
+	return map[string]any{
+		"application":     p.Application,
+		"namespace":       p.Namespace,
+		"revisions":       strings.Join(p.Revisions, ","),
+		"environment_url": p.EnvironmentURL,
+		"pull_requests":   strings.Join(pullRequests, ","),
+	}
+}
+
+// CreateDispatch sends a repository_dispatch or workflow_dispatch event.
+// https://docs.github.com/en/rest/repos/repos#create-a-repository-dispatch-event
+// https://docs.github.com/en/rest/actions/workflows#create-a-workflow-dispatch-event
+func (c *client) CreateDispatch(ctx context.Context, d Dispatch, p DispatchPayload) error {
+	if d.EventType != "" {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("could not encode the payload: %w", err)
+		}
+		clientPayload := json.RawMessage(b)
+		_, _, err = c.rest.Repositories.Dispatch(ctx, d.Repository.Owner, d.Repository.Name, github.DispatchRequestOptions{
+			EventType:     d.EventType,
+			ClientPayload: &clientPayload,
+		})
+		if err != nil {
+			return fmt.Errorf("could not create a repository_dispatch event %s: %w", d.EventType, err)
+		}
+		return nil
+	}
+
+	ref := d.Ref
+	if ref == "" {
+		repository, _, err := c.rest.Repositories.Get(ctx, d.Repository.Owner, d.Repository.Name)
+		if err != nil {
+			return fmt.Errorf("could not get the default branch: %w", err)
+		}
+		ref = repository.GetDefaultBranch()
+	}
+	_, err := c.rest.Actions.CreateWorkflowDispatchEventByFileName(ctx, d.Repository.Owner, d.Repository.Name, d.Workflow,
+		github.CreateWorkflowDispatchEventRequest{Ref: ref, Inputs: p.workflowInputs()})
+	if err != nil {
+		return fmt.Errorf("could not create a workflow_dispatch event of %s: %w", d.Workflow, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDispatch(t *testing.T) {
+	for s, want := range map[string]*Dispatch{
+		"repo=owner/tests,event-type=smoke-test": {
+			Repository: Repository{Owner: "owner", Name: "tests"},
+			EventType:  "smoke-test",
+		},
+		"repo=owner/tests, workflow=e2e.yaml, ref=main": {
+			Repository: Repository{Owner: "owner", Name: "tests"},
+			Workflow:   "e2e.yaml",
+			Ref:        "main",
+		},
+		"":                          nil,
+		"repo=owner/tests":          nil,
+		"event-type=smoke-test":     nil,
+		"repo=owner,event-type=foo": nil,
+		"repo=owner/tests,event-type=smoke-test,workflow=e2e.yaml": nil,
+		"repo=owner/tests,unknown=foo":                             nil,
+	} {
+		t.Run(s, func(t *testing.T) {
+			got := ParseDispatch(s)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
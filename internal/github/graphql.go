@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphqlURL returns the endpoint of GraphQL API corresponding to the base URL of REST API.
+// For example,
+// https://api.github.com/ => https://api.github.com/graphql
+// https://ghes.example.com/api/v3/ => https://ghes.example.com/api/graphql
+func graphqlURL(restBaseURL *url.URL) string {
+	u := *restBaseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "/v3/") + "/graphql"
+		return u.String()
+	}
+	u.Path = "/graphql"
+	return u.String()
+}
+
+// queryGraphQL sends a query or mutation to GraphQL API.
+// If data is non-nil, it is decoded from the data field of the response.
+func (c *client) queryGraphQL(ctx context.Context, query string, variables map[string]any, data any) error {
+	req, err := c.rest.NewRequest(http.MethodPost, graphqlURL(c.rest.BaseURL), graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("could not create a GraphQL request: %w", err)
+	}
+	var resp graphqlResponse
+	if _, err := c.rest.Do(ctx, req, &resp); err != nil {
+		return fmt.Errorf("GitHub GraphQL API error: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		var errs []error
+		for _, e := range resp.Errors {
+			errs = append(errs, errors.New(e.Message))
+		}
+		return fmt.Errorf("GitHub GraphQL API error: %w", errors.Join(errs...))
+	}
+	if data == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, data); err != nil {
+		return fmt.Errorf("could not decode the GraphQL response: %w", err)
+	}
+	return nil
+}
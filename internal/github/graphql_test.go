@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+func Test_graphqlURL(t *testing.T) {
+	t.Run("github.com", func(t *testing.T) {
+		u, err := url.Parse("https://api.github.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := graphqlURL(u), "https://api.github.com/graphql"; got != want {
+			t.Errorf("want %s but was %s", want, got)
+		}
+	})
+
+	t.Run("GitHub Enterprise Server", func(t *testing.T) {
+		u, err := url.Parse("https://ghes.example.com/api/v3/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := graphqlURL(u), "https://ghes.example.com/api/graphql"; got != want {
+			t.Errorf("want %s but was %s", want, got)
+		}
+	})
+}
+
+func TestListAuthoredPullRequestComments(t *testing.T) {
+	var sv githubmock.Server
+	sv.Handle("POST /api/graphql", respondJSON(t, map[string]any{
+		"data": map[string]any{
+			"repository": map[string]any{
+				"pullRequest": map[string]any{
+					"comments": map[string]any{
+						"nodes": []map[string]any{
+							{"id": "IC_1", "databaseId": 1, "body": "by someone", "viewerDidAuthor": false},
+							{"id": "IC_2", "databaseId": 2, "body": "old", "isMinimized": true, "viewerDidAuthor": true},
+							{"id": "IC_3", "databaseId": 3, "body": "new", "viewerDidAuthor": true},
+						},
+					},
+				},
+			},
+		},
+	}))
+	ghc := newMockClient(t, &sv)
+	got, err := ghc.ListAuthoredPullRequestComments(context.TODO(), Repository{Owner: "owner", Name: "repo"}, 1)
+	if err != nil {
+		t.Fatalf("ListAuthoredPullRequestComments error: %s", err)
+	}
+	want := []IssueComment{
+		{ID: 2, NodeID: "IC_2", Body: "old", IsMinimized: true},
+		{ID: 3, NodeID: "IC_3", Body: "new"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
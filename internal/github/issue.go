@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v80/github"
+)
+
+type Issue struct {
+	Repository Repository
+	Number     int
+	// Body is set only if the issue is retrieved by REST API.
+	Body string
+}
+
+const closingIssuesReferencesQuery = `query ($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      closingIssuesReferences(first: 50) {
+        nodes {
+          number
+          repository {
+            owner {
+              login
+            }
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+type closingIssuesReferencesData struct {
+	Repository struct {
+		PullRequest struct {
+			ClosingIssuesReferences struct {
+				Nodes []struct {
+					Number     int `json:"number"`
+					Repository struct {
+						Owner struct {
+							Login string `json:"login"`
+						} `json:"owner"`
+						Name string `json:"name"`
+					} `json:"repository"`
+				} `json:"nodes"`
+			} `json:"closingIssuesReferences"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// ListClosingIssues returns the issues closed by the pull request, such as "Fixes #123".
+// https://docs.github.com/en/graphql/reference/objects#pullrequest
+func (c *client) ListClosingIssues(ctx context.Context, r Repository, pullNumber int) ([]Issue, error) {
+	var data closingIssuesReferencesData
+	variables := map[string]any{"owner": r.Owner, "name": r.Name, "number": pullNumber}
+	if err := c.queryGraphQL(ctx, closingIssuesReferencesQuery, variables, &data); err != nil {
+		return nil, fmt.Errorf("could not list the closing issues of the pull request #%d: %w", pullNumber, err)
+	}
+	var issues []Issue
+	for _, node := range data.Repository.PullRequest.ClosingIssuesReferences.Nodes {
+		issues = append(issues, Issue{
+			Repository: Repository{Owner: node.Repository.Owner.Login, Name: node.Repository.Name},
+			Number:     node.Number,
+		})
+	}
+	return issues, nil
+}
+
+// FindOpenIssueByLabel returns the oldest open issue with the label, or nil if not found.
+// It bypasses the HTTP cache, because the caller edits the issue based on the latest body.
+func (c *client) FindOpenIssueByLabel(ctx context.Context, r Repository, label string) (*Issue, error) {
+	u := fmt.Sprintf("repos/%s/%s/issues?state=open&labels=%s&sort=created&direction=asc&per_page=100",
+		r.Owner, r.Name, url.QueryEscape(label))
+	req, err := c.rest.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a request: %w", err)
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	var ghIssues []*github.Issue
+	if _, err := c.rest.Do(ctx, req, &ghIssues); err != nil {
+		return nil, fmt.Errorf("could not list issues with the label %s: %w", label, err)
+	}
+	for _, ghIssue := range ghIssues {
+		if ghIssue.IsPullRequest() {
+			continue
+		}
+		return &Issue{Repository: r, Number: ghIssue.GetNumber(), Body: ghIssue.GetBody()}, nil
+	}
+	return nil, nil
+}
+
+func (c *client) CreateIssue(ctx context.Context, r Repository, title, body string, labels []string) (*Issue, error) {
+	ghIssue, _, err := c.rest.Issues.Create(ctx, r.Owner, r.Name, &github.IssueRequest{
+		Title:  github.Ptr(title),
+		Body:   github.Ptr(body),
+		Labels: &labels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create an issue: %w", err)
+	}
+	return &Issue{Repository: r, Number: ghIssue.GetNumber(), Body: ghIssue.GetBody()}, nil
+}
+
+func (c *client) EditIssueBody(ctx context.Context, r Repository, number int, body string) error {
+	_, _, err := c.rest.Issues.Edit(ctx, r.Owner, r.Name, number, &github.IssueRequest{Body: github.Ptr(body)})
+	if err != nil {
+		return fmt.Errorf("could not edit the issue #%d: %w", number, err)
+	}
+	return nil
+}
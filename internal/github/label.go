@@ -0,0 +1,52 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+)
+
+func (c *client) AddLabelsToPullRequest(ctx context.Context, r Repository, pullNumber int, labels []string) error {
+	_, _, err := c.rest.Issues.AddLabelsToIssue(ctx, r.Owner, r.Name, pullNumber, labels)
+	if err != nil {
+		return fmt.Errorf("could not add labels %v to the pull request #%d: %w", labels, pullNumber, err)
+	}
+	return nil
+}
+
+// RemoveLabelFromPullRequest removes the label from the pull request.
+// It does nothing if the pull request does not have the label.
+func (c *client) RemoveLabelFromPullRequest(ctx context.Context, r Repository, pullNumber int, label string) error {
+	_, err := c.rest.Issues.RemoveLabelForIssue(ctx, r.Owner, r.Name, pullNumber, label)
+	if IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not remove the label %s from the pull request #%d: %w", label, pullNumber, err)
+	}
+	return nil
+}
+
+// ListPullRequestNumbersByLabel returns the numbers of closed pull requests with the label.
+// It returns at most limit pull requests in the recently updated first order,
+// because a label may remain on many old pull requests.
+func (c *client) ListPullRequestNumbersByLabel(ctx context.Context, r Repository, label string, limit int) ([]int, error) {
+	issues, _, err := c.rest.Issues.ListByRepo(ctx, r.Owner, r.Name, &github.IssueListByRepoOptions{
+		State:       "closed",
+		Labels:      []string{label},
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: limit},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list issues with the label %s: %w", label, err)
+	}
+	var numbers []int
+	for _, issue := range issues {
+		if issue.IsPullRequest() {
+			numbers = append(numbers, issue.GetNumber())
+		}
+	}
+	return numbers, nil
+}
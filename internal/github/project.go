@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const projectItemsQuery = `query ($owner: String!, $name: String!, $number: Int!, $fieldName: String!) {
+  repository(owner: $owner, name: $name) {
+    issueOrPullRequest(number: $number) {
+      ... on Issue {
+        projectItems(first: 20) {
+          ...projectItems
+        }
+      }
+      ... on PullRequest {
+        projectItems(first: 20) {
+          ...projectItems
+        }
+      }
+    }
+  }
+}
+
+fragment projectItems on ProjectV2ItemConnection {
+  nodes {
+    id
+    project {
+      id
+      field(name: $fieldName) {
+        ... on ProjectV2SingleSelectField {
+          id
+          options {
+            id
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+type projectItemsData struct {
+	Repository struct {
+		IssueOrPullRequest struct {
+			ProjectItems struct {
+				Nodes []struct {
+					ID      string `json:"id"`
+					Project struct {
+						ID    string `json:"id"`
+						Field *struct {
+							ID      string `json:"id"`
+							Options []struct {
+								ID   string `json:"id"`
+								Name string `json:"name"`
+							} `json:"options"`
+						} `json:"field"`
+					} `json:"project"`
+				} `json:"nodes"`
+			} `json:"projectItems"`
+		} `json:"issueOrPullRequest"`
+	} `json:"repository"`
+}
+
+const updateProjectItemFieldValueMutation = `mutation ($projectId: ID!, $itemId: ID!, $fieldId: ID!, $optionId: String!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $projectId, itemId: $itemId, fieldId: $fieldId, value: {singleSelectOptionId: $optionId}}) {
+    projectV2Item {
+      id
+    }
+  }
+}`
+
+// SetProjectItemsFieldValue sets the option of the single select field to the project items of the issue or pull request.
+// It skips a project which does not have the field or option.
+// It returns the number of the updated items.
+// https://docs.github.com/en/issues/planning-and-tracking-with-projects/automating-your-project/using-the-api-to-manage-projects
+func (c *client) SetProjectItemsFieldValue(ctx context.Context, r Repository, number int, fieldName, optionName string) (int, error) {
+	var data projectItemsData
+	variables := map[string]any{"owner": r.Owner, "name": r.Name, "number": number, "fieldName": fieldName}
+	if err := c.queryGraphQL(ctx, projectItemsQuery, variables, &data); err != nil {
+		return 0, fmt.Errorf("could not list the project items of #%d: %w", number, err)
+	}
+	var updated int
+	var errs []error
+	for _, item := range data.Repository.IssueOrPullRequest.ProjectItems.Nodes {
+		field := item.Project.Field
+		if field == nil || field.ID == "" {
+			continue
+		}
+		for _, option := range field.Options {
+			if option.Name != optionName {
+				continue
+			}
+			variables := map[string]any{
+				"projectId": item.Project.ID,
+				"itemId":    item.ID,
+				"fieldId":   field.ID,
+				"optionId":  option.ID,
+			}
+			if err := c.queryGraphQL(ctx, updateProjectItemFieldValueMutation, variables, nil); err != nil {
+				errs = append(errs, fmt.Errorf("could not update the project item %s: %w", item.ID, err))
+				continue
+			}
+			updated++
+		}
+	}
+	return updated, errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+func TestSetProjectItemsFieldValue(t *testing.T) {
+	var queryVariables []map[string]any
+	var mutationVariables []map[string]any
+	var sv githubmock.Server
+	sv.Handle("POST /api/graphql", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("could not decode the request: %s", err)
+			return
+		}
+		if strings.HasPrefix(req.Query, "mutation") {
+			mutationVariables = append(mutationVariables, req.Variables)
+			respondJSON(t, map[string]any{"data": map[string]any{}})(w, r)
+			return
+		}
+		queryVariables = append(queryVariables, req.Variables)
+		respondJSON(t, map[string]any{
+			"data": map[string]any{
+				"repository": map[string]any{
+					"issueOrPullRequest": map[string]any{
+						"projectItems": map[string]any{
+							"nodes": []map[string]any{
+								{
+									"id": "PVTI_1",
+									"project": map[string]any{
+										"id": "PVT_1",
+										"field": map[string]any{
+											"id": "PVTSSF_1",
+											"options": []map[string]any{
+												{"id": "OPT_1", "name": "staging"},
+												{"id": "OPT_2", "name": "production"},
+											},
+										},
+									},
+								},
+								// The project does not have the field.
+								{"id": "PVTI_2", "project": map[string]any{"id": "PVT_2", "field": nil}},
+								// The field is not a single select field.
+								{"id": "PVTI_3", "project": map[string]any{"id": "PVT_3", "field": map[string]any{}}},
+								// The field does not have the option.
+								{
+									"id": "PVTI_4",
+									"project": map[string]any{
+										"id": "PVT_4",
+										"field": map[string]any{
+											"id":      "PVTSSF_4",
+											"options": []map[string]any{{"id": "OPT_3", "name": "staging"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})(w, r)
+	}))
+	ghc := newMockClient(t, &sv)
+	updated, err := ghc.SetProjectItemsFieldValue(context.TODO(), Repository{Owner: "owner", Name: "repo"}, 1, "Environment", "production")
+	if err != nil {
+		t.Fatalf("SetProjectItemsFieldValue error: %s", err)
+	}
+	if updated != 1 {
+		t.Errorf("updated wants 1 but was %d", updated)
+	}
+	wantQueryVariables := []map[string]any{
+		{"owner": "owner", "name": "repo", "number": float64(1), "fieldName": "Environment"},
+	}
+	if diff := cmp.Diff(wantQueryVariables, queryVariables); diff != "" {
+		t.Errorf("query variables mismatch (-want +got):\n%s", diff)
+	}
+	wantMutationVariables := []map[string]any{
+		{"projectId": "PVT_1", "itemId": "PVTI_1", "fieldId": "PVTSSF_1", "optionId": "OPT_2"},
+	}
+	if diff := cmp.Diff(wantMutationVariables, mutationVariables); diff != "" {
+		t.Errorf("mutation variables mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -3,6 +3,10 @@ package github
 import (
 	"context"
 	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v80/github"
 )
 
 func (c *client) ListPullRequests(ctx context.Context, r Repository, revision string) ([]PullRequest, error) {
@@ -12,15 +16,114 @@ func (c *client) ListPullRequests(ctx context.Context, r Repository, revision st
 	}
 	var pulls []PullRequest
 	for _, pr := range ghPulls {
-		prFiles, _, err := c.rest.PullRequests.ListFiles(ctx, r.Owner, r.Name, pr.GetNumber(), nil)
+		pull, err := c.newPullRequest(ctx, r, pr)
+		if err != nil {
+			return nil, err
+		}
+		pulls = append(pulls, *pull)
+	}
+	return pulls, nil
+}
+
+// ListPullRequestsInComparison returns the pull requests associated with the commits in the comparison.
+// If there is no commit, such as a rollback, it returns the pull requests associated with head.
+// It inspects the latest commits up to maxCommitsInComparison.
+func (c *client) ListPullRequestsInComparison(ctx context.Context, r Repository, comparison Comparison) ([]PullRequest, error) {
+	if len(comparison.CommitSHAs) == 0 {
+		return c.ListPullRequests(ctx, r, comparison.HeadRevision)
+	}
+	if dropped := comparison.TotalCommits - len(comparison.CommitSHAs); dropped > 0 {
+		logr.FromContextOrDiscard(ctx).Info("Inspecting only the latest commits to find the pull requests",
+			"base", comparison.BaseRevision, "head", comparison.HeadRevision,
+			"totalCommits", comparison.TotalCommits, "droppedCommits", dropped)
+	}
+
+	var pulls []PullRequest
+	var seen []int
+	for _, sha := range comparison.CommitSHAs {
+		ghPulls, _, err := c.rest.PullRequests.ListPullRequestsWithCommit(ctx, r.Owner, r.Name, sha, nil)
 		if err != nil {
-			return nil, fmt.Errorf("could not list files of pull request #%d: %w", pr.GetNumber(), err)
+			return nil, fmt.Errorf("could not list pull requests with commit %s: %w", sha, err)
 		}
-		var files []string
-		for _, f := range prFiles {
-			files = append(files, f.GetFilename())
+		for _, pr := range ghPulls {
+			if slices.Contains(seen, pr.GetNumber()) {
+				continue
+			}
+			seen = append(seen, pr.GetNumber())
+			pull, err := c.newPullRequest(ctx, r, pr)
+			if err != nil {
+				return nil, err
+			}
+			pulls = append(pulls, *pull)
 		}
-		pulls = append(pulls, PullRequest{Number: pr.GetNumber(), Files: files})
 	}
 	return pulls, nil
 }
+
+// GetPullRequest returns the pull request with the files.
+func (c *client) GetPullRequest(ctx context.Context, r Repository, pullNumber int) (*PullRequest, error) {
+	pr, _, err := c.rest.PullRequests.Get(ctx, r.Owner, r.Name, pullNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not get pull request #%d: %w", pullNumber, err)
+	}
+	return c.newPullRequest(ctx, r, pr)
+}
+
+func (c *client) newPullRequest(ctx context.Context, r Repository, pr *github.PullRequest) (*PullRequest, error) {
+	prFiles, _, err := c.rest.PullRequests.ListFiles(ctx, r.Owner, r.Name, pr.GetNumber(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not list files of pull request #%d: %w", pr.GetNumber(), err)
+	}
+	var files []string
+	for _, f := range prFiles {
+		files = append(files, f.GetFilename())
+	}
+	var requestedReviewers []string
+	for _, u := range pr.RequestedReviewers {
+		requestedReviewers = append(requestedReviewers, u.GetLogin())
+	}
+	for _, t := range pr.RequestedTeams {
+		requestedReviewers = append(requestedReviewers, fmt.Sprintf("%s/%s", r.Owner, t.GetSlug()))
+	}
+	var labels []string
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
+	var mergeCommitSHA string
+	if pr.MergedAt != nil {
+		mergeCommitSHA = pr.GetMergeCommitSHA()
+	}
+	return &PullRequest{
+		Number:             pr.GetNumber(),
+		HeadSHA:            pr.GetHead().GetSHA(),
+		MergeCommitSHA:     mergeCommitSHA,
+		Files:              files,
+		Author:             pr.GetUser().GetLogin(),
+		RequestedReviewers: requestedReviewers,
+		Labels:             labels,
+	}, nil
+}
+
+// GetPullRequestParticipants returns the users who merged or reviewed the pull request.
+// The list API does not return them, so this requires the API calls for each pull request.
+func (c *client) GetPullRequestParticipants(ctx context.Context, r Repository, pullNumber int) (*PullRequestParticipants, error) {
+	pr, _, err := c.rest.PullRequests.Get(ctx, r.Owner, r.Name, pullNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not get pull request #%d: %w", pullNumber, err)
+	}
+	reviews, _, err := c.rest.PullRequests.ListReviews(ctx, r.Owner, r.Name, pullNumber, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("could not list reviews of pull request #%d: %w", pullNumber, err)
+	}
+	var reviewers []string
+	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if login != "" && !slices.Contains(reviewers, login) {
+			reviewers = append(reviewers, login)
+		}
+	}
+	return &PullRequestParticipants{
+		MergedBy:  pr.GetMergedBy().GetLogin(),
+		Reviewers: reviewers,
+	}, nil
+}
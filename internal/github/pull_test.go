@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+func TestListPullRequestsInComparison(t *testing.T) {
+	repository := Repository{Owner: "owner", Name: "repo"}
+
+	t.Run("commits", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/commits/sha1/pulls", respondJSON(t, []*github.PullRequest{
+			{Number: github.Ptr(1), User: &github.User{Login: github.Ptr("alice")}, Head: &github.PullRequestBranch{SHA: github.Ptr("head1")}},
+		}))
+		// It should not get a merged pull request, because merged_by is fetched lazily.
+		sv.Handle("GET /api/v3/repos/owner/repo/commits/sha2/pulls", respondJSON(t, []*github.PullRequest{
+			{Number: github.Ptr(1), User: &github.User{Login: github.Ptr("alice")}},
+			{Number: github.Ptr(2), User: &github.User{Login: github.Ptr("bob")}, MergedAt: &github.Timestamp{}},
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/1/files", respondJSON(t, []*github.CommitFile{{Filename: github.Ptr("app/a.yaml")}}))
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/2/files", respondJSON(t, []*github.CommitFile{{Filename: github.Ptr("app/b.yaml")}}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.ListPullRequestsInComparison(context.TODO(), repository, Comparison{
+			BaseRevision: "base",
+			HeadRevision: "sha2",
+			TotalCommits: 2,
+			CommitSHAs:   []string{"sha1", "sha2"},
+		})
+		if err != nil {
+			t.Fatalf("ListPullRequestsInComparison error: %s", err)
+		}
+		want := []PullRequest{
+			{Number: 1, HeadSHA: "head1", Files: []string{"app/a.yaml"}, Author: "alice"},
+			{Number: 2, Files: []string{"app/b.yaml"}, Author: "bob"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no commit", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/commits/head/pulls", respondJSON(t, []*github.PullRequest{
+			{Number: github.Ptr(3)},
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/3/files", respondJSON(t, []*github.CommitFile{}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.ListPullRequestsInComparison(context.TODO(), repository, Comparison{
+			BaseRevision: "base",
+			HeadRevision: "head",
+		})
+		if err != nil {
+			t.Fatalf("ListPullRequestsInComparison error: %s", err)
+		}
+		want := []PullRequest{{Number: 3}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestGetPullRequestParticipants(t *testing.T) {
+	var sv githubmock.Server
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/1", respondJSON(t, github.PullRequest{
+		Number:   github.Ptr(1),
+		MergedBy: &github.User{Login: github.Ptr("bob")},
+	}))
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/1/reviews?per_page=100", respondJSON(t, []*github.PullRequestReview{
+		{User: &github.User{Login: github.Ptr("carol")}, State: github.Ptr("COMMENTED")},
+		{User: &github.User{Login: github.Ptr("carol")}, State: github.Ptr("APPROVED")},
+		{User: &github.User{Login: github.Ptr("dave")}, State: github.Ptr("APPROVED")},
+	}))
+	ghc := newMockClient(t, &sv)
+	got, err := ghc.GetPullRequestParticipants(context.TODO(), Repository{Owner: "owner", Name: "repo"}, 1)
+	if err != nil {
+		t.Fatalf("GetPullRequestParticipants error: %s", err)
+	}
+	want := &PullRequestParticipants{MergedBy: "bob", Reviewers: []string{"carol", "dave"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	repository := Repository{Owner: "owner", Name: "repo"}
+
+	t.Run("merged", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/1", respondJSON(t, &github.PullRequest{
+			Number:         github.Ptr(1),
+			MergedAt:       &github.Timestamp{},
+			MergeCommitSHA: github.Ptr("merge1"),
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/1/files", respondJSON(t, []*github.CommitFile{{Filename: github.Ptr("app/a.yaml")}}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.GetPullRequest(context.TODO(), repository, 1)
+		if err != nil {
+			t.Fatalf("GetPullRequest error: %s", err)
+		}
+		want := &PullRequest{Number: 1, MergeCommitSHA: "merge1", Files: []string{"app/a.yaml"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not merged", func(t *testing.T) {
+		var sv githubmock.Server
+		// GitHub returns a test merge commit of an open pull request.
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/2", respondJSON(t, &github.PullRequest{
+			Number:         github.Ptr(2),
+			MergeCommitSHA: github.Ptr("test-merge2"),
+		}))
+		sv.Handle("GET /api/v3/repos/owner/repo/pulls/2/files", respondJSON(t, []*github.CommitFile{}))
+		ghc := newMockClient(t, &sv)
+		got, err := ghc.GetPullRequest(context.TODO(), repository, 2)
+		if err != nil {
+			t.Fatalf("GetPullRequest error: %s", err)
+		}
+		want := &PullRequest{Number: 2}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// Content of a reaction.
+// https://docs.github.com/en/rest/reactions/reactions#about-reactions
+const (
+	ReactionEyes     = "eyes"
+	ReactionRocket   = "rocket"
+	ReactionConfused = "confused"
+)
+
+type Reaction struct {
+	ID      int64
+	Content string
+	User    string
+}
+
+func newReaction(r *github.Reaction) Reaction {
+	return Reaction{
+		ID:      r.GetID(),
+		Content: r.GetContent(),
+		User:    r.GetUser().GetLogin(),
+	}
+}
+
+// CreatePullRequestReaction adds a reaction to the pull request.
+// If the same reaction already exists, GitHub returns it without creating a new one.
+func (c *client) CreatePullRequestReaction(ctx context.Context, r Repository, pullNumber int, content string) (*Reaction, error) {
+	reaction, _, err := c.rest.Reactions.CreateIssueReaction(ctx, r.Owner, r.Name, pullNumber, content)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a reaction %s to the pull request #%d: %w", content, pullNumber, err)
+	}
+	created := newReaction(reaction)
+	return &created, nil
+}
+
+// ListPullRequestReactions returns the reactions to the pull request.
+// It returns only the first page, which is enough to find the reactions of this application.
+func (c *client) ListPullRequestReactions(ctx context.Context, r Repository, pullNumber int) ([]Reaction, error) {
+	reactions, _, err := c.rest.Reactions.ListIssueReactions(ctx, r.Owner, r.Name, pullNumber, &github.ListReactionOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the reactions to the pull request #%d: %w", pullNumber, err)
+	}
+	var result []Reaction
+	for _, reaction := range reactions {
+		result = append(result, newReaction(reaction))
+	}
+	return result, nil
+}
+
+// DeletePullRequestReaction deletes the reaction from the pull request.
+func (c *client) DeletePullRequestReaction(ctx context.Context, r Repository, pullNumber int, reactionID int64) error {
+	if _, err := c.rest.Reactions.DeleteIssueReaction(ctx, r.Owner, r.Name, pullNumber, reactionID); err != nil {
+		return fmt.Errorf("could not delete the reaction %d from the pull request #%d: %w", reactionID, pullNumber, err)
+	}
+	return nil
+}
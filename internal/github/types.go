@@ -10,9 +10,38 @@ import (
 
 type Client interface {
 	ListPullRequests(ctx context.Context, r Repository, revision string) ([]PullRequest, error)
+	ListPullRequestsInComparison(ctx context.Context, r Repository, comparison Comparison) ([]PullRequest, error)
+	GetPullRequest(ctx context.Context, r Repository, pullNumber int) (*PullRequest, error)
+	GetPullRequestParticipants(ctx context.Context, r Repository, pullNumber int) (*PullRequestParticipants, error)
+	CompareRevisions(ctx context.Context, r Repository, base, head string) (*Comparison, error)
+	GetComparisonStatus(ctx context.Context, r Repository, base, head string) (string, error)
+	GetCodeOwners(ctx context.Context, r Repository, ref string) (CodeOwners, error)
+	GetFileContent(ctx context.Context, r Repository, ref, path string) (string, error)
 	CreatePullRequestComment(ctx context.Context, r Repository, pullNumber int, body string) error
+	ListPullRequestComments(ctx context.Context, r Repository, pullNumber int) ([]IssueComment, error)
+	EditPullRequestComment(ctx context.Context, r Repository, commentID int64, body string) error
+	ListAuthoredPullRequestComments(ctx context.Context, r Repository, pullNumber int) ([]IssueComment, error)
+	MinimizeOutdatedComment(ctx context.Context, nodeID string) error
+	CreatePullRequestReaction(ctx context.Context, r Repository, pullNumber int, content string) (*Reaction, error)
+	ListPullRequestReactions(ctx context.Context, r Repository, pullNumber int) ([]Reaction, error)
+	DeletePullRequestReaction(ctx context.Context, r Repository, pullNumber int, reactionID int64) error
+	AddLabelsToPullRequest(ctx context.Context, r Repository, pullNumber int, labels []string) error
+	RemoveLabelFromPullRequest(ctx context.Context, r Repository, pullNumber int, label string) error
+	ListPullRequestNumbersByLabel(ctx context.Context, r Repository, label string, limit int) ([]int, error)
+	ListClosingIssues(ctx context.Context, r Repository, pullNumber int) ([]Issue, error)
+	FindOpenIssueByLabel(ctx context.Context, r Repository, label string) (*Issue, error)
+	CreateIssue(ctx context.Context, r Repository, title, body string, labels []string) (*Issue, error)
+	EditIssueBody(ctx context.Context, r Repository, number int, body string) error
+	CreateDispatch(ctx context.Context, d Dispatch, p DispatchPayload) error
+	SetProjectItemsFieldValue(ctx context.Context, r Repository, number int, fieldName, optionName string) (int, error)
 	CreateCommitComment(ctx context.Context, r Repository, sha, body string) error
+	CreateOrUpdateCheckRun(ctx context.Context, r Repository, cr CheckRun) error
+	CreateCommitStatus(ctx context.Context, r Repository, sha string, cs CommitStatus) error
+	ParseDeploymentURL(s string) *Deployment
+	CreateDeployment(ctx context.Context, r Repository, dr DeploymentRequest) (string, error)
 	CreateDeploymentStatus(ctx context.Context, d Deployment, ds DeploymentStatus) error
+	FindLatestDeploymentURL(ctx context.Context, q DeploymentQuery, revision string) (string, error)
+	ListPreviousDeployments(ctx context.Context, d Deployment, limit int) ([]Deployment, error)
 	FindLatestDeploymentStatus(ctx context.Context, d Deployment) (*DeploymentStatus, error)
 }
 
@@ -54,7 +83,24 @@ func parseRepositorySSH(s string) *Repository {
 
 type PullRequest struct {
 	Number int
-	Files  []string
+	// SHA of the head commit of the pull request.
+	HeadSHA string
+	// SHA of the merge commit, or empty if not merged.
+	MergeCommitSHA string
+	Files          []string
+	// Login of the user who opened the pull request.
+	Author string
+	// Logins of the requested reviewers, and "org/team" of the requested teams.
+	RequestedReviewers []string
+	Labels             []string
+}
+
+// PullRequestParticipants represents the users who merged or reviewed a pull request.
+type PullRequestParticipants struct {
+	// Login of the user who merged the pull request, or empty if not merged.
+	MergedBy string
+	// Logins of the users who submitted a review.
+	Reviewers []string
 }
 
 func IsNotFoundError(err error) bool {
@@ -0,0 +1,182 @@
+package githubmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/go-github/v80/github"
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+func ListPullRequestsWithCommit(number int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(200)
+		Expect(json.NewEncoder(w).Encode([]*github.PullRequest{{Number: github.Ptr(number)}})).Should(Succeed())
+	}
+}
+
+func ListPullRequestFiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(200)
+		Expect(json.NewEncoder(w).Encode([]*github.CommitFile{{Filename: github.Ptr("test/deployment.yaml")}})).Should(Succeed())
+	}
+}
+
+type recorder struct {
+	counter atomic.Int32
+}
+
+func (e *recorder) Count() int {
+	return int(e.counter.Load())
+}
+
+// Comments is an in-memory store of the comments of a pull request.
+type Comments struct {
+	mu       sync.Mutex
+	comments []*github.IssueComment
+}
+
+func (s *Comments) add(body string) *github.IssueComment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := &github.IssueComment{ID: github.Ptr(int64(len(s.comments) + 1)), Body: github.Ptr(body)}
+	s.comments = append(s.comments, c)
+	return c
+}
+
+func (s *Comments) edit(id int64, body string) *github.IssueComment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.comments {
+		if c.GetID() == id {
+			c.Body = github.Ptr(body)
+			return c
+		}
+	}
+	return nil
+}
+
+// Bodies returns the bodies of the stored comments.
+func (s *Comments) Bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var bodies []string
+	for _, c := range s.comments {
+		bodies = append(bodies, c.GetBody())
+	}
+	return bodies
+}
+
+func (s *Comments) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+	Expect(json.NewEncoder(w).Encode(s.comments)).Should(Succeed())
+}
+
+type CreateComment struct {
+	recorder
+	// If set, the created comment is stored.
+	Store *Comments
+}
+
+func (e *CreateComment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.counter.Add(1)
+	var req github.IssueComment
+	Expect(json.NewDecoder(r.Body).Decode(&req)).Should(Succeed())
+	GinkgoWriter.Println("GITHUB", "created comment", req)
+	resp := &req
+	if e.Store != nil {
+		resp = e.Store.add(req.GetBody())
+	}
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+	Expect(json.NewEncoder(w).Encode(resp)).Should(Succeed())
+}
+
+type EditComment struct {
+	recorder
+	Store *Comments
+	ID    int64
+}
+
+func (e *EditComment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.counter.Add(1)
+	var req github.IssueComment
+	Expect(json.NewDecoder(r.Body).Decode(&req)).Should(Succeed())
+	GinkgoWriter.Println("GITHUB", "edited comment", e.ID, req)
+	resp := e.Store.edit(e.ID, req.GetBody())
+	if resp == nil {
+		http.Error(w, fmt.Sprintf("comment %d not found", e.ID), http.StatusNotFound)
+		return
+	}
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+	Expect(json.NewEncoder(w).Encode(resp)).Should(Succeed())
+}
+
+type ListDeploymentStatus struct {
+	Response []*github.DeploymentStatus
+}
+
+func (e *ListDeploymentStatus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+	Expect(json.NewEncoder(w).Encode(e.Response)).Should(Succeed())
+}
+
+type CreateDeploymentStatus struct {
+	recorder
+}
+
+func (e *CreateDeploymentStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.counter.Add(1)
+	var req github.DeploymentStatusRequest
+	Expect(json.NewDecoder(r.Body).Decode(&req)).Should(Succeed())
+	GinkgoWriter.Println("GITHUB", "created deployment status", req)
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+}
+
+// RecordRequests records the request bodies and responds with the JSON.
+type RecordRequests struct {
+	recorder
+	// Response is encoded to JSON. If nil, an empty object is returned.
+	Response any
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+// Bodies returns the recorded request bodies.
+func (e *RecordRequests) Bodies() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.bodies)
+}
+
+func (e *RecordRequests) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	Expect(err).NotTo(HaveOccurred())
+	GinkgoWriter.Println("GITHUB", "recorded request", string(body))
+	e.mu.Lock()
+	e.bodies = append(e.bodies, string(body))
+	e.mu.Unlock()
+	e.counter.Add(1)
+	resp := e.Response
+	if resp == nil {
+		resp = struct{}{}
+	}
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(200)
+	Expect(json.NewEncoder(w).Encode(resp)).Should(Succeed())
+}
@@ -0,0 +1,162 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// maxCheckRunTextLength is the maximum length of the text of a check run output.
+const maxCheckRunTextLength = 65535
+
+// CreateOrUpdateCheckRuns creates or updates the check run named after the application on each source revision.
+// It also creates or updates the check run on the head commit of each related pull request,
+// because GitHub shows the annotations only in the pull request of the commit.
+func (c client) CreateOrUpdateCheckRuns(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, loadRelatedPulls RelatedPullRequestsLoader) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	var errs []error
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+		if repository == nil {
+			continue
+		}
+		checkRun := generateCheckRun(app, argocdURL, sourceRevision)
+		if checkRun == nil {
+			return nil
+		}
+		if err := c.ghc.CreateOrUpdateCheckRun(ctx, *repository, *checkRun); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Created or updated the check run",
+			"revision", checkRun.HeadSHA, "status", checkRun.Status, "conclusion", checkRun.Conclusion)
+	}
+
+	relatedPullsOfSources, err := loadRelatedPulls()
+	errs = append(errs, err)
+	for _, relatedPulls := range relatedPullsOfSources {
+		checkRun := generateCheckRun(app, argocdURL, relatedPulls.SourceRevision)
+		if checkRun == nil {
+			continue
+		}
+		if err := c.createOrUpdateCheckRunsOfPullRequests(ctx, app, relatedPulls, *checkRun); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// createOrUpdateCheckRunsOfPullRequests creates or updates the check run on the head commit of each related pull request.
+// The annotations point to the manifests of the failed resources in the head commit.
+func (c client) createOrUpdateCheckRunsOfPullRequests(ctx context.Context, app argocdv1alpha1.Application,
+	relatedPulls RelatedPullRequests, checkRun github.CheckRun) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	sourceRevision := relatedPulls.SourceRevision
+	failedResources := getFailedResourcesForCheckRun(app)
+	manifestGeneratePaths := getManifestGeneratePaths(app)
+	var errs []error
+	for _, pull := range relatedPulls.PullRequests {
+		if pull.HeadSHA == "" || pull.HeadSHA == sourceRevision.Revision {
+			continue
+		}
+		pullCheckRun := checkRun
+		pullCheckRun.HeadSHA = pull.HeadSHA
+		pullCheckRun.Annotations = c.generateCheckRunAnnotations(ctx, relatedPulls.Repository, pull,
+			getFilesRelatedToEvent(pull, sourceRevision, manifestGeneratePaths), failedResources)
+		if err := c.ghc.CreateOrUpdateCheckRun(ctx, relatedPulls.Repository, pullCheckRun); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Created or updated the check run of the pull request",
+			"pullNumber", pull.Number, "revision", pullCheckRun.HeadSHA, "annotations", len(pullCheckRun.Annotations))
+	}
+	return errors.Join(errs...)
+}
+
+func generateCheckRun(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision) *github.CheckRun {
+	phase := argocd.GetSyncOperationPhase(app)
+	if phase == "" {
+		return nil
+	}
+	argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, app.Name)
+	checkRun := github.CheckRun{
+		Name:       getApplicationQualifiedName(app),
+		HeadSHA:    sourceRevision.Revision,
+		DetailsURL: argocdApplicationURL,
+		Summary: fmt.Sprintf("[%s](%s) at %s: sync %s, health %s",
+			app.Name, argocdApplicationURL, sourceRevision.Revision, phase, app.Status.Health.Status),
+	}
+	switch phase {
+	case synccommon.OperationRunning, synccommon.OperationTerminating:
+		checkRun.Status = "in_progress"
+		checkRun.Title = "Syncing"
+	case synccommon.OperationFailed, synccommon.OperationError:
+		checkRun.Status = "completed"
+		checkRun.Conclusion = "failure"
+		checkRun.Title = fmt.Sprintf("Sync %s", phase)
+		checkRun.Text = generateCheckRunText(getFailedResourcesForCheckRun(app))
+	case synccommon.OperationSucceeded:
+		switch app.Status.Health.Status {
+		case health.HealthStatusHealthy:
+			checkRun.Status = "completed"
+			checkRun.Conclusion = "success"
+			checkRun.Title = "Healthy"
+		case health.HealthStatusDegraded:
+			checkRun.Status = "completed"
+			checkRun.Conclusion = "failure"
+			checkRun.Title = "Degraded"
+			checkRun.Text = generateCheckRunText(getFailedResourcesForCheckRun(app))
+		default:
+			checkRun.Status = "in_progress"
+			checkRun.Title = string(app.Status.Health.Status)
+		}
+	default:
+		return nil
+	}
+	return &checkRun
+}
+
+// getApplicationQualifiedName returns the name of the application to identify it in a repository.
+// If the application is outside the namespace of Argo CD (apps-in-any-namespace),
+// it returns the name prefixed with the namespace, because the name is unique only in a namespace.
+func getApplicationQualifiedName(app argocdv1alpha1.Application) string {
+	argocdNamespace := os.Getenv("ARGOCD_NAMESPACE")
+	if argocdNamespace == "" || app.Namespace == "" || app.Namespace == argocdNamespace {
+		return app.Name
+	}
+	return fmt.Sprintf("%s/%s", app.Namespace, app.Name)
+}
+
+// getFailedResourcesForCheckRun returns the failed resources if the check run is failure.
+func getFailedResourcesForCheckRun(app argocdv1alpha1.Application) []FailedResource {
+	switch argocd.GetSyncOperationPhase(app) {
+	case synccommon.OperationFailed, synccommon.OperationError:
+		return getFailedResourcesOnPhaseChanged(app)
+	case synccommon.OperationSucceeded:
+		if app.Status.Health.Status == health.HealthStatusDegraded {
+			return getFailedResourcesOnHealthChanged(app)
+		}
+	}
+	return nil
+}
+
+func generateCheckRunText(failedResources []FailedResource) string {
+	if len(failedResources) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Failed resources\n")
+	for _, r := range failedResources {
+		fmt.Fprintf(&b, "- %s %s `%s/%s`: %s\n", r.Status, r.Kind, r.Namespace, r.Name, r.Message)
+	}
+	return truncateText(b.String(), maxCheckRunTextLength)
+}
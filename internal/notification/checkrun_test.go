@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateCheckRun(t *testing.T) {
+	const revision = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa101"
+	sourceRevision := argocd.SourceRevision{Revision: revision}
+	newApp := func(phase synccommon.OperationPhase, healthStatus health.HealthStatusCode) argocdv1alpha1.Application {
+		return argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1"},
+			Status: argocdv1alpha1.ApplicationStatus{
+				OperationState: &argocdv1alpha1.OperationState{Phase: phase},
+				Health:         argocdv1alpha1.AppHealthStatus{Status: healthStatus},
+			},
+		}
+	}
+
+	t.Run("no sync operation", func(t *testing.T) {
+		got := generateCheckRun(argocdv1alpha1.Application{}, "https://argocd.example.com", sourceRevision)
+		if got != nil {
+			t.Errorf("generateCheckRun wants nil but was %+v", got)
+		}
+	})
+
+	t.Run("syncing", func(t *testing.T) {
+		got := generateCheckRun(newApp(synccommon.OperationRunning, health.HealthStatusHealthy), "https://argocd.example.com", sourceRevision)
+		want := &github.CheckRun{
+			Name:       "app1",
+			HeadSHA:    revision,
+			Status:     "in_progress",
+			DetailsURL: "https://argocd.example.com/applications/app1",
+			Title:      "Syncing",
+			Summary:    "[app1](https://argocd.example.com/applications/app1) at " + revision + ": sync Running, health Healthy",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("application outside the namespace of Argo CD", func(t *testing.T) {
+		t.Setenv("ARGOCD_NAMESPACE", "argocd")
+		app := newApp(synccommon.OperationRunning, health.HealthStatusHealthy)
+		app.Namespace = "team1"
+		got := generateCheckRun(app, "https://argocd.example.com", sourceRevision)
+		if got.Name != "team1/app1" {
+			t.Errorf("Name wants team1/app1 but was %s", got.Name)
+		}
+	})
+
+	t.Run("progressing", func(t *testing.T) {
+		got := generateCheckRun(newApp(synccommon.OperationSucceeded, health.HealthStatusProgressing), "https://argocd.example.com", sourceRevision)
+		if got.Status != "in_progress" || got.Conclusion != "" || got.Title != "Progressing" {
+			t.Errorf("unexpected check run %+v", got)
+		}
+	})
+
+	t.Run("healthy", func(t *testing.T) {
+		got := generateCheckRun(newApp(synccommon.OperationSucceeded, health.HealthStatusHealthy), "https://argocd.example.com", sourceRevision)
+		if got.Status != "completed" || got.Conclusion != "success" {
+			t.Errorf("unexpected check run %+v", got)
+		}
+	})
+
+	t.Run("sync failed", func(t *testing.T) {
+		app := newApp(synccommon.OperationFailed, health.HealthStatusHealthy)
+		app.Status.OperationState.SyncResult = &argocdv1alpha1.SyncOperationResult{
+			Resources: argocdv1alpha1.ResourceResults{
+				{Kind: "Deployment", Namespace: "default", Name: "foo", Status: synccommon.ResultCodeSyncFailed, Message: "invalid spec"},
+				{Kind: "Service", Namespace: "default", Name: "foo", Status: synccommon.ResultCodeSynced, Message: "configured"},
+			},
+		}
+		got := generateCheckRun(app, "https://argocd.example.com", sourceRevision)
+		if got.Status != "completed" || got.Conclusion != "failure" || got.Title != "Sync Failed" {
+			t.Errorf("unexpected check run %+v", got)
+		}
+		const wantText = "## Failed resources\n- SyncFailed Deployment `default/foo`: invalid spec\n"
+		if diff := cmp.Diff(wantText, got.Text); diff != "" {
+			t.Errorf("text mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		app := newApp(synccommon.OperationSucceeded, health.HealthStatusDegraded)
+		app.Status.Resources = []argocdv1alpha1.ResourceStatus{
+			{Kind: "Deployment", Namespace: "default", Name: "foo",
+				Health: &argocdv1alpha1.HealthStatus{Status: health.HealthStatusDegraded, Message: "crash loop"}},
+		}
+		got := generateCheckRun(app, "https://argocd.example.com", sourceRevision)
+		if got.Status != "completed" || got.Conclusion != "failure" || got.Title != "Degraded" {
+			t.Errorf("unexpected check run %+v", got)
+		}
+		const wantText = "## Failed resources\n- Degraded Deployment `default/foo`: crash loop\n"
+		if diff := cmp.Diff(wantText, got.Text); diff != "" {
+			t.Errorf("text mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
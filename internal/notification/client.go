@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
 	"github.com/go-logr/logr"
 	"github.com/int128/argocd-commenter/internal/argocd"
 	"github.com/int128/argocd-commenter/internal/github"
@@ -15,29 +18,81 @@ import (
 type Client interface {
 	CreateCommentsOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
 	CreateCommentsOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
-	CreateDeploymentStatusOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
-	CreateDeploymentStatusOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
-	CreateDeploymentStatusOnDeletion(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
+	CreateDeployment(ctx context.Context, app argocdv1alpha1.Application) (string, error)
+	// CreateDeploymentStatusOn* return the state of the created deployment status,
+	// or empty string if no deployment status is created.
+	CreateDeploymentStatusOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error)
+	CreateDeploymentStatusOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error)
+	CreateDeploymentStatusOnDeletion(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error)
+	CreateDeploymentStatusOnRollback(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error)
+	DeactivateSupersededDeployments(ctx context.Context, app argocdv1alpha1.Application, deploymentURL string, ownedDeploymentURLs []string) ([]SupersededDeployment, error)
+	CreateDeploymentStatusOnProgressDeadlineExceeded(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error)
+	CreateCommentsOnProgressDeadlineExceeded(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, progressDeadline time.Duration) error
+	UpdateSummaryComments(ctx context.Context, app argocdv1alpha1.Application, apps []argocdv1alpha1.Application, argocdURL string) error
+	CreateOrUpdateCheckRuns(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, loadRelatedPulls RelatedPullRequestsLoader) error
+	CreateCommitStatuses(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
+	UpdateDeploymentLog(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error
 
+	// NewRelatedPullRequestsLoader returns a loader shared by the following hooks of a transition.
+	NewRelatedPullRequestsLoader(ctx context.Context, app argocdv1alpha1.Application) RelatedPullRequestsLoader
+	UpdateDeploymentLabels(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error
+	NotifyClosingIssues(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, loadRelatedPulls RelatedPullRequestsLoader) error
+	UpdateProjectFields(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error
+	CreateDispatch(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error
+
+	IsRollback(ctx context.Context, app argocdv1alpha1.Application) (bool, error)
 	CheckIfDeploymentIsAlreadyHealthy(ctx context.Context, deploymentURL string) (bool, error)
+	ResolveDeploymentURL(ctx context.Context, deploymentQuery, revision string) (string, error)
 }
 
-func NewClient(ghc github.Client) Client {
-	return &client{ghc: ghc}
+// NewClient returns a Client.
+// If templateLoader is nil, the built-in texts are used.
+// If deploymentStatusStatesOnHealth is nil, the default mapping is used.
+func NewClient(ghc github.Client, templateLoader TemplateLoader, deploymentStatusStatesOnHealth map[health.HealthStatusCode]string) Client {
+	return &client{
+		ghc:                            ghc,
+		templateLoader:                 templateLoader,
+		deploymentStatusStatesOnHealth: deploymentStatusStatesOnHealth,
+		commentMu:                      &sync.Mutex{},
+	}
 }
 
 func IsNotFoundError(err error) bool {
 	return github.IsNotFoundError(err)
 }
 
+// IsDeploymentQuery returns true if the string is a deployment query instead of URL.
+func IsDeploymentQuery(s string) bool {
+	return github.ParseDeploymentQuery(s) != nil
+}
+
 type Comment struct {
 	GitHubRepository github.Repository
 	SourceRevision   argocd.SourceRevision
 	Body             string
+	// Time of the transition shown in the timeline.
+	Time time.Time
+	// Failed is true if the comment notifies a failure.
+	Failed bool
+	// Reaction to the pull request in the reaction mode, or empty if nothing to react.
+	Reaction string
+	// Comparison between the previous and current revisions, or nil if not available.
+	// This is used to find the pull requests merged since the previous revision.
+	Comparison *github.Comparison
 }
 
 type client struct {
-	ghc github.Client
+	ghc                            github.Client
+	templateLoader                 TemplateLoader
+	deploymentStatusStatesOnHealth map[health.HealthStatusCode]string
+	commentMu                      *sync.Mutex
+}
+
+func (c client) getDeploymentStatusStatesOnHealth() map[health.HealthStatusCode]string {
+	if c.deploymentStatusStatesOnHealth == nil {
+		return defaultDeploymentStatusStatesOnHealth
+	}
+	return c.deploymentStatusStatesOnHealth
 }
 
 func (c client) createComment(ctx context.Context, comment Comment, app argocdv1alpha1.Application) error {
@@ -45,16 +100,27 @@ func (c client) createComment(ctx context.Context, comment Comment, app argocdv1
 		"revision", comment.SourceRevision.Revision,
 		"repository", comment.GitHubRepository,
 	)
-	pulls, err := c.ghc.ListPullRequests(ctx, comment.GitHubRepository, comment.SourceRevision.Revision)
+	var pulls []github.PullRequest
+	var err error
+	if comment.SourceRevision.Rollback {
+		pulls, err = c.listRolledBackPullRequests(ctx, comment.GitHubRepository, comment.SourceRevision)
+	} else {
+		pulls, err = c.listPullRequestsInComparison(ctx, comment.GitHubRepository, comment.SourceRevision, comment.Comparison)
+	}
 	if err != nil {
 		return fmt.Errorf("unable to list pull requests of revision %s: %w", comment.SourceRevision.Revision, err)
 	}
 	relatedPulls := filterPullRequestsRelatedToEvent(pulls, comment.SourceRevision, app)
+	if len(relatedPulls) == 0 && getNotificationMode(app) == NotificationModeReaction {
+		logger.Info("No pull request to react")
+		return nil
+	}
 	if len(relatedPulls) == 0 {
 		logger.Info("No pull request related to the revision")
 		// This may cause a secondary rate limit error of GitHub API.
 		if os.Getenv("FEATURE_CREATE_COMMIT_COMMENT") == "true" {
-			if err := c.ghc.CreateCommitComment(ctx, comment.GitHubRepository, comment.SourceRevision.Revision, comment.Body); err != nil {
+			if err := c.ghc.CreateCommitComment(ctx, comment.GitHubRepository, comment.SourceRevision.Revision,
+				truncateText(comment.Body, maxCommentBodyLength)); err != nil {
 				return fmt.Errorf("unable to create a comment on revision %s: %w", comment.SourceRevision.Revision, err)
 			}
 			logger.Info("Created a comment to the commit")
@@ -62,13 +128,31 @@ func (c client) createComment(ctx context.Context, comment Comment, app argocdv1
 		return nil
 	}
 
+	if getNotificationMode(app) == NotificationModeReaction {
+		return c.createReactions(ctx, comment, relatedPulls)
+	}
+
+	var codeOwners github.CodeOwners
+	if comment.Failed && isMentionOnFailureEnabled() {
+		codeOwners = c.loadCodeOwners(ctx, comment)
+	}
+	manifestGeneratePaths := getManifestGeneratePaths(app)
 	var errs []error
 	for _, pull := range relatedPulls {
-		if err := c.ghc.CreatePullRequestComment(ctx, comment.GitHubRepository, pull.Number, comment.Body); err != nil {
+		if err := c.createOrUpdatePullRequestComment(ctx, comment, app, pull.Number); err != nil {
 			errs = append(errs, err)
 			continue
 		}
-		logger.Info("Created a comment to the pull request", "pullNumber", pull.Number)
+		if !comment.Failed || !isMentionOnFailureEnabled() {
+			continue
+		}
+		relatedFiles := getFilesRelatedToEvent(pull, comment.SourceRevision, manifestGeneratePaths)
+		mentions := generateMentions(pull, relatedFiles, codeOwners, func() *github.PullRequestParticipants {
+			return c.getPullRequestParticipants(ctx, comment.GitHubRepository, pull.Number)
+		})
+		if err := c.mentionPullRequest(ctx, comment, app, pull.Number, mentions); err != nil {
+			errs = append(errs, err)
+		}
 	}
 	if err := errors.Join(errs...); err != nil {
 		return fmt.Errorf("unable to create comment(s) on revision %s: %w", comment.SourceRevision.Revision, err)
@@ -76,6 +160,62 @@ func (c client) createComment(ctx context.Context, comment Comment, app argocdv1
 	return nil
 }
 
+// listPullRequests returns the pull requests merged since the previous revision.
+// If the previous revision is unknown, it returns the pull requests associated with the current revision.
+func (c client) listPullRequests(ctx context.Context, repository github.Repository, sourceRevision argocd.SourceRevision) ([]github.PullRequest, error) {
+	return c.listPullRequestsInComparison(ctx, repository, sourceRevision, c.compareRevisions(ctx, sourceRevision))
+}
+
+// listPullRequestsInComparison returns the pull requests of the commits in the comparison.
+// If comparison is nil, it returns the pull requests associated with the current revision.
+func (c client) listPullRequestsInComparison(ctx context.Context, repository github.Repository, sourceRevision argocd.SourceRevision, comparison *github.Comparison) ([]github.PullRequest, error) {
+	if comparison == nil {
+		return c.ghc.ListPullRequests(ctx, repository, sourceRevision.Revision)
+	}
+	return c.ghc.ListPullRequestsInComparison(ctx, repository, *comparison)
+}
+
+// RelatedPullRequests represents the pull requests related to the application in a source.
+type RelatedPullRequests struct {
+	Repository     github.Repository
+	SourceRevision argocd.SourceRevision
+	PullRequests   []github.PullRequest
+}
+
+// RelatedPullRequestsLoader returns the related pull requests of each source.
+// If an error occurred in a source, it returns the other sources with the error.
+type RelatedPullRequestsLoader func() ([]RelatedPullRequests, error)
+
+// NewRelatedPullRequestsLoader returns a loader which lists the pull requests at most once.
+// The pull requests are listed lazily, so that no API call is made if no hook needs them.
+func (c client) NewRelatedPullRequestsLoader(ctx context.Context, app argocdv1alpha1.Application) RelatedPullRequestsLoader {
+	return sync.OnceValues(func() ([]RelatedPullRequests, error) {
+		return c.listRelatedPullRequests(ctx, app)
+	})
+}
+
+func (c client) listRelatedPullRequests(ctx context.Context, app argocdv1alpha1.Application) ([]RelatedPullRequests, error) {
+	var relatedPullsOfSources []RelatedPullRequests
+	var errs []error
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+		if repository == nil {
+			continue
+		}
+		pulls, err := c.listPullRequests(ctx, *repository, sourceRevision)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to list pull requests of revision %s: %w", sourceRevision.Revision, err))
+			continue
+		}
+		relatedPullsOfSources = append(relatedPullsOfSources, RelatedPullRequests{
+			Repository:     *repository,
+			SourceRevision: sourceRevision,
+			PullRequests:   filterPullRequestsRelatedToEvent(pulls, sourceRevision, app),
+		})
+	}
+	return relatedPullsOfSources, errors.Join(errs...)
+}
+
 type DeploymentStatus struct {
 	GitHubDeployment       github.Deployment
 	GitHubDeploymentStatus github.DeploymentStatus
@@ -94,7 +234,7 @@ func (c client) createDeploymentStatus(ctx context.Context, ds DeploymentStatus)
 }
 
 func (c client) CheckIfDeploymentIsAlreadyHealthy(ctx context.Context, deploymentURL string) (bool, error) {
-	deployment := github.ParseDeploymentURL(deploymentURL)
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
 	if deployment == nil {
 		return false, nil
 	}
@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/int128/argocd-commenter/internal/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+)
+
+// newMockClient returns a client connected to the mock server.
+func newMockClient(t *testing.T, sv *githubmock.Server) client {
+	t.Helper()
+	s := httptest.NewServer(sv)
+	t.Cleanup(s.Close)
+	t.Setenv("GITHUB_TOKEN", "dummy-github-token")
+	t.Setenv("GITHUB_ENTERPRISE_URL", s.URL)
+	ghc, err := github.NewClient(context.TODO())
+	if err != nil {
+		t.Fatalf("NewClient error: %s", err)
+	}
+	return client{ghc: ghc}
+}
+
+// respondJSON returns a handler which responds the value as JSON.
+func respondJSON(t *testing.T, v any) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("could not encode the response: %s", err)
+		}
+	}
+}
+
+// recordRequests returns a handler which appends the request to the slice and responds an empty array.
+func recordRequests(requests *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.RequestURI)
+		w.Header().Add("content-type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	}
+}
@@ -0,0 +1,251 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+const (
+	timelineBeginMarker = "<!-- argocd-commenter:timeline -->"
+	timelineEndMarker   = "<!-- /argocd-commenter:timeline -->"
+)
+
+// createOrUpdatePullRequestComment creates a comment of the application,
+// or updates the existing comment by appending the transition to the timeline.
+func (c client) createOrUpdatePullRequestComment(ctx context.Context, comment Comment, app argocdv1alpha1.Application, pullNumber int) error {
+	marker := applicationCommentMarker(app)
+	entry := generateTimelineEntry(comment)
+	if err := c.upsertPullRequestComment(ctx, comment.GitHubRepository, pullNumber, marker,
+		func(existingComment *github.IssueComment) string {
+			if existingComment == nil {
+				return generateApplicationCommentBody(marker, comment.Body, []string{entry})
+			}
+			timeline := appendTimelineEntry(parseTimeline(existingComment.Body), entry)
+			return generateApplicationCommentBody(marker, comment.Body, timeline)
+		}); err != nil {
+		return err
+	}
+	if os.Getenv("FEATURE_MINIMIZE_OUTDATED_COMMENTS") == "true" {
+		c.minimizeOutdatedComments(ctx, comment.GitHubRepository, pullNumber, app)
+	}
+	return nil
+}
+
+// upsertPullRequestComment creates a comment containing the marker, or edits the existing one.
+// generateBody receives the existing comment, or nil if not found.
+func (c client) upsertPullRequestComment(ctx context.Context, repository github.Repository, pullNumber int, marker string,
+	generateBody func(existingComment *github.IssueComment) string) error {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("pullNumber", pullNumber)
+	// Multiple controllers may update the same comment concurrently.
+	c.commentMu.Lock()
+	defer c.commentMu.Unlock()
+
+	comments, err := c.ghc.ListPullRequestComments(ctx, repository, pullNumber)
+	if err != nil {
+		return fmt.Errorf("unable to list comments of pull request #%d: %w", pullNumber, err)
+	}
+	markedComments := filterCommentsByMarker(comments, marker)
+	if len(markedComments) == 0 {
+		body := truncateText(generateBody(nil), maxCommentBodyLength)
+		if err := c.ghc.CreatePullRequestComment(ctx, repository, pullNumber, body); err != nil {
+			return err
+		}
+		logger.Info("Created a comment to the pull request")
+		return nil
+	}
+
+	// The latest comment supersedes the others.
+	existingComment := markedComments[len(markedComments)-1]
+	body := truncateText(generateBody(&existingComment), maxCommentBodyLength)
+	if body == existingComment.Body {
+		logger.Info("Comment is already up-to-date", "commentID", existingComment.ID)
+		return nil
+	}
+	if err := c.ghc.EditPullRequestComment(ctx, repository, existingComment.ID, body); err != nil {
+		return err
+	}
+	logger.Info("Updated the comment of the pull request", "commentID", existingComment.ID)
+	return nil
+}
+
+// minimizeOutdatedComments hides the older comments of the application as outdated.
+// This requires the permission to write GraphQL API, and it is optional.
+// An error is logged and does not stop the notification.
+func (c client) minimizeOutdatedComments(ctx context.Context, repository github.Repository, pullNumber int, app argocdv1alpha1.Application) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("pullNumber", pullNumber)
+	comments, err := c.ghc.ListAuthoredPullRequestComments(ctx, repository, pullNumber)
+	if err != nil {
+		logger.Error(err, "unable to list the comments to minimize")
+		return
+	}
+	for _, comment := range findOutdatedComments(comments, app) {
+		if err := c.ghc.MinimizeOutdatedComment(ctx, comment.NodeID); err != nil {
+			logger.Error(err, "unable to minimize the outdated comment", "commentID", comment.ID)
+			continue
+		}
+		logger.Info("Minimized the outdated comment", "commentID", comment.ID)
+	}
+}
+
+// findOutdatedComments returns the comments of the application superseded by the latest one.
+// It includes the legacy comments without the marker, which were created before the timeline is introduced.
+// It excludes the comments already minimized.
+func findOutdatedComments(comments []github.IssueComment, app argocdv1alpha1.Application) []github.IssueComment {
+	marker := applicationCommentMarker(app)
+	markedComments := filterCommentsByMarker(comments, marker)
+	var latestID int64
+	if len(markedComments) > 0 {
+		latestID = markedComments[len(markedComments)-1].ID
+	}
+	var outdatedComments []github.IssueComment
+	for _, comment := range comments {
+		if comment.IsMinimized || comment.ID == latestID {
+			continue
+		}
+		if strings.Contains(comment.Body, marker) || isLegacyApplicationComment(comment.Body, app) {
+			outdatedComments = append(outdatedComments, comment)
+		}
+	}
+	return outdatedComments
+}
+
+// isLegacyApplicationComment returns true if the comment is created for the application without any marker,
+// such as "## :x: Failed to sync [app](https://argocd.example.com/applications/app) to main".
+// A legacy comment has only the name of the application, so it is matched only if the application is
+// in the namespace of Argo CD, that is ARGOCD_NAMESPACE or "argocd" by default.
+// This avoids hiding the comments of another application of the same name in another namespace.
+func isLegacyApplicationComment(body string, app argocdv1alpha1.Application) bool {
+	if strings.Contains(body, "<!-- argocd-commenter:") {
+		return false
+	}
+	argocdNamespace := os.Getenv("ARGOCD_NAMESPACE")
+	if argocdNamespace == "" {
+		argocdNamespace = "argocd"
+	}
+	if app.Namespace != argocdNamespace {
+		return false
+	}
+	return strings.Contains(body, fmt.Sprintf("[%s](", app.Name)) &&
+		strings.Contains(body, fmt.Sprintf("/applications/%s)", app.Name))
+}
+
+// applicationCommentMarker returns a hidden marker to find the comment of the application.
+func applicationCommentMarker(app argocdv1alpha1.Application) string {
+	return fmt.Sprintf("<!-- argocd-commenter:application=%s/%s -->", app.Namespace, app.Name)
+}
+
+// filterCommentsByMarker returns the comments containing the marker in the original order.
+func filterCommentsByMarker(comments []github.IssueComment, marker string) []github.IssueComment {
+	var markedComments []github.IssueComment
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			markedComments = append(markedComments, comment)
+		}
+	}
+	return markedComments
+}
+
+// generateTimelineEntry returns a line of the timeline.
+// It consists of the time and the first line of the comment body.
+func generateTimelineEntry(comment Comment) string {
+	return fmt.Sprintf("- %s %s", comment.Time.UTC().Format(time.RFC3339), summarizeCommentBody(comment.Body))
+}
+
+// summarizeCommentBody returns the first line of the comment body without the heading.
+func summarizeCommentBody(body string) string {
+	summary, _, _ := strings.Cut(body, "\n")
+	return strings.TrimLeft(summary, "# ")
+}
+
+// parseTimeline returns the entries of the timeline in the comment body.
+func parseTimeline(body string) []string {
+	_, afterBegin, ok := strings.Cut(body, timelineBeginMarker)
+	if !ok {
+		return nil
+	}
+	section, _, _ := strings.Cut(afterBegin, timelineEndMarker)
+	var timeline []string
+	for line := range strings.SplitSeq(section, "\n") {
+		if strings.HasPrefix(line, "- ") {
+			timeline = append(timeline, line)
+		}
+	}
+	return timeline
+}
+
+func appendTimelineEntry(timeline []string, entry string) []string {
+	if len(timeline) > 0 && timeline[len(timeline)-1] == entry {
+		return timeline
+	}
+	return append(timeline, entry)
+}
+
+// generateApplicationCommentBody returns the comment body within the maximum length.
+// If the timeline is too long, the oldest entries are dropped.
+// If the body is too long, it is truncated to keep the timeline.
+func generateApplicationCommentBody(marker, body string, timeline []string) string {
+	timelineSection := generateTimelineSection(timeline)
+	for len(timeline) > 1 && len(timelineSection) > maxCommentBodyLength/2 {
+		timeline = timeline[1:]
+		timelineSection = generateTimelineSection(timeline)
+	}
+	maxBodyLength := maxCommentBodyLength - len(marker) - len(timelineSection) - 3
+	var b strings.Builder
+	fmt.Fprintln(&b, marker)
+	fmt.Fprintln(&b, truncateText(strings.TrimRight(body, "\n"), maxBodyLength))
+	fmt.Fprintln(&b)
+	b.WriteString(timelineSection)
+	return b.String()
+}
+
+func generateTimelineSection(timeline []string) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "### Timeline")
+	fmt.Fprintln(&b, timelineBeginMarker)
+	for _, entry := range timeline {
+		fmt.Fprintln(&b, entry)
+	}
+	fmt.Fprintln(&b, timelineEndMarker)
+	return b.String()
+}
+
+// maxCommentBodyLength is the maximum length of a comment body accepted by GitHub.
+const maxCommentBodyLength = 65536
+
+const truncatedSuffix = "\n\n... (truncated)"
+
+const detailsEndTag = "\n</details>"
+
+// truncateText returns the text within the maximum length in bytes.
+// If the text is truncated, it ends with the suffix.
+// It cuts at the end of a line if possible, and closes the <details> blocks left open,
+// so that the rest of the comment is not collapsed.
+func truncateText(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	cut := max(maxLength-len(truncatedSuffix), 0)
+	for {
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		head := s[:cut]
+		if i := strings.LastIndex(head, "\n"); i > 0 {
+			head = head[:i]
+		}
+		openDetails := max(strings.Count(head, "<details>")-strings.Count(head, "</details>"), 0)
+		closing := strings.Repeat(detailsEndTag, openDetails)
+		if len(head)+len(closing)+len(truncatedSuffix) <= maxLength || cut == 0 {
+			return head + closing + truncatedSuffix
+		}
+		cut = max(cut-(len(head)+len(closing)+len(truncatedSuffix)-maxLength), 0)
+	}
+}
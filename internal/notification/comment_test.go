@@ -0,0 +1,168 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_parseTimeline(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		timeline := []string{
+			"- 2021-01-01T00:00:00Z :warning: Syncing [app1](https://argocd.example.com/applications/app1) to main",
+			"- 2021-01-01T00:01:00Z :white_check_mark: Synced [app1](https://argocd.example.com/applications/app1) to main",
+		}
+		body := generateApplicationCommentBody("<!-- marker -->", "## :x: Failed\n- resource\n", timeline)
+		got := parseTimeline(body)
+		if diff := cmp.Diff(timeline, got); diff != "" {
+			t.Errorf("timeline mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no timeline", func(t *testing.T) {
+		got := parseTimeline("Synced app1")
+		if got != nil {
+			t.Errorf("timeline wants nil but was %+v", got)
+		}
+	})
+}
+
+func Test_generateTimelineEntry(t *testing.T) {
+	comment := Comment{
+		Body: "## :x: Failed to sync [app1](https://argocd.example.com/applications/app1) to main\n- SyncFailed `default/app1`: error\n",
+		Time: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	got := generateTimelineEntry(comment)
+	const want = "- 2021-01-02T03:04:05Z :x: Failed to sync [app1](https://argocd.example.com/applications/app1) to main"
+	if want != got {
+		t.Errorf("entry wants %s but was %s", want, got)
+	}
+}
+
+func Test_appendTimelineEntry(t *testing.T) {
+	timeline := []string{"- a", "- b"}
+	if got := appendTimelineEntry(timeline, "- b"); len(got) != 2 {
+		t.Errorf("duplicated entry should not be appended but was %+v", got)
+	}
+	if got := appendTimelineEntry(timeline, "- c"); len(got) != 3 {
+		t.Errorf("new entry should be appended but was %+v", got)
+	}
+}
+
+func Test_filterCommentsByMarker(t *testing.T) {
+	comments := []github.IssueComment{
+		{ID: 1, Body: "<!-- argocd-commenter:application=default/app1 -->\nold"},
+		{ID: 2, Body: "<!-- argocd-commenter:application=default/app2 -->"},
+		{ID: 3, Body: "<!-- argocd-commenter:application=default/app1 -->\nnew"},
+	}
+	got := filterCommentsByMarker(comments, "<!-- argocd-commenter:application=default/app1 -->")
+	want := []github.IssueComment{comments[0], comments[2]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("comments mismatch (-want +got):\n%s", diff)
+	}
+	if got := filterCommentsByMarker(comments, "<!-- argocd-commenter:application=default/app3 -->"); got != nil {
+		t.Errorf("want nil but was %+v", got)
+	}
+}
+
+func Test_findOutdatedComments(t *testing.T) {
+	comments := []github.IssueComment{
+		{ID: 1, Body: "## :x: Failed to sync [app1](https://argocd.example.com/applications/app1) to main\n"},
+		{ID: 2, Body: "## :x: Failed to sync [app2](https://argocd.example.com/applications/app2) to main\n"},
+		{ID: 3, Body: "<!-- argocd-commenter:application=argocd/app1 -->\nold", IsMinimized: true},
+		{ID: 4, Body: "<!-- argocd-commenter:application=argocd/app1 -->\nold"},
+		{ID: 5, Body: "<!-- argocd-commenter:summary -->\n[app1](https://argocd.example.com/applications/app1)"},
+		{ID: 6, Body: "<!-- argocd-commenter:application=argocd/app1 -->\nnew"},
+		{ID: 7, Body: "<!-- argocd-commenter:application=team1/app1 -->\nnew"},
+	}
+
+	t.Run("application in the namespace of Argo CD", func(t *testing.T) {
+		app := argocdv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "argocd", Name: "app1"}}
+		got := findOutdatedComments(comments, app)
+		want := []github.IssueComment{comments[0], comments[3]}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("comments mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("application of the same name in another namespace", func(t *testing.T) {
+		app := argocdv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "team1", Name: "app1"}}
+		got := findOutdatedComments(comments, app)
+		if len(got) != 0 {
+			t.Errorf("want empty but was %+v", got)
+		}
+	})
+}
+
+func Test_generateApplicationCommentBody(t *testing.T) {
+	const marker = "<!-- marker -->"
+	t.Run("long body", func(t *testing.T) {
+		timeline := []string{"- 2021-01-01T00:00:00Z :x: Failed to sync"}
+		got := generateApplicationCommentBody(marker, strings.Repeat("x", 100000), timeline)
+		if len(got) > maxCommentBodyLength {
+			t.Errorf("length wants <= %d but was %d", maxCommentBodyLength, len(got))
+		}
+		if !strings.Contains(got, truncatedSuffix) {
+			t.Errorf("body should be truncated")
+		}
+		if diff := cmp.Diff(timeline, parseTimeline(got)); diff != "" {
+			t.Errorf("timeline mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("long timeline", func(t *testing.T) {
+		var timeline []string
+		for i := range 2000 {
+			timeline = append(timeline, fmt.Sprintf("- %04d %s", i, strings.Repeat("x", 100)))
+		}
+		got := generateApplicationCommentBody(marker, strings.Repeat("y", 50000), timeline)
+		if len(got) > maxCommentBodyLength {
+			t.Errorf("length wants <= %d but was %d", maxCommentBodyLength, len(got))
+		}
+		gotTimeline := parseTimeline(got)
+		if len(gotTimeline) == 0 || gotTimeline[len(gotTimeline)-1] != timeline[len(timeline)-1] {
+			t.Errorf("timeline should keep the latest entry")
+		}
+		if gotTimeline[0] == timeline[0] {
+			t.Errorf("timeline should drop the oldest entries")
+		}
+	})
+}
+
+func Test_truncateText(t *testing.T) {
+	if got := truncateText("abc", 3); got != "abc" {
+		t.Errorf("want abc but was %s", got)
+	}
+	got := truncateText(strings.Repeat("あ", 100), 50)
+	if len(got) > 50 {
+		t.Errorf("length wants <= 50 but was %d", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("text should be valid UTF-8 but was %q", got)
+	}
+
+	t.Run("details block", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("Synced\n\n<details>\n<summary>Resources</summary>\n\n")
+		for i := range 100 {
+			fmt.Fprintf(&b, "- `default/resource-%03d`: configured\n", i)
+		}
+		b.WriteString("</details>\n")
+		got := truncateText(b.String(), 500)
+		if len(got) > 500 {
+			t.Errorf("length wants <= 500 but was %d", len(got))
+		}
+		if strings.Count(got, "<details>") != strings.Count(got, "</details>") {
+			t.Errorf("details block should be closed but was %q", got)
+		}
+		if !strings.HasSuffix(got, "configured\n</details>"+truncatedSuffix) {
+			t.Errorf("text should be cut at the end of a line but was %q", got)
+		}
+	})
+}
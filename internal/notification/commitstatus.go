@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// commitStatusStates maps a state of deployment status to a state of commit status.
+// A commit status has no inactive state, so it is mapped to pending.
+var commitStatusStates = map[string]string{
+	"queued":      "pending",
+	"in_progress": "pending",
+	"pending":     "pending",
+	"inactive":    "pending",
+	"success":     "success",
+	"failure":     "failure",
+	"error":       "error",
+}
+
+// CreateCommitStatuses creates a commit status of the application on each source revision.
+func (c client) CreateCommitStatuses(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	templates := c.loadTemplates(ctx, app.Namespace)
+	cs := generateCommitStatus(ctx, app, argocdURL, c.getDeploymentStatusStatesOnHealth(), templates)
+	if cs == nil {
+		return nil
+	}
+	var errs []error
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+		if repository == nil {
+			continue
+		}
+		if err := c.ghc.CreateCommitStatus(ctx, *repository, sourceRevision.Revision, *cs); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Created a commit status", "revision", sourceRevision.Revision, "state", cs.State)
+	}
+	return errors.Join(errs...)
+}
+
+// generateCommitStatus returns a commit status in the same way as the deployment status.
+// If the sync operation is succeeded, the health status determines the state.
+func generateCommitStatus(ctx context.Context, app argocdv1alpha1.Application, argocdURL string,
+	deploymentStatusStatesOnHealth map[health.HealthStatusCode]string, templates *Templates) *github.CommitStatus {
+	var deploymentState, description string
+	phase := argocd.GetSyncOperationPhase(app)
+	healthState, ok := deploymentStatusStatesOnHealth[app.Status.Health.Status]
+	if phase == synccommon.OperationSucceeded && ok {
+		deploymentState = healthState
+		description = generateDeploymentStatusDescriptionOnHealthChanged(ctx, app, argocdURL, templates)
+	} else {
+		deploymentState = deploymentStatusStatesOnPhase[phase]
+		description = generateDeploymentStatusDescriptionOnPhaseChanged(ctx, app, argocdURL, templates)
+	}
+	state, ok := commitStatusStates[deploymentState]
+	if !ok {
+		return nil
+	}
+	return &github.CommitStatus{
+		Context:     fmt.Sprintf("argocd/%s", getApplicationQualifiedName(app)),
+		State:       state,
+		Description: trimDescription(description),
+		TargetURL:   fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
+	}
+}
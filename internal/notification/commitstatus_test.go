@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateCommitStatus(t *testing.T) {
+	for _, tc := range []struct {
+		phase     synccommon.OperationPhase
+		health    health.HealthStatusCode
+		wantState string
+	}{
+		{phase: synccommon.OperationRunning, health: health.HealthStatusHealthy, wantState: "pending"},
+		{phase: synccommon.OperationSucceeded, health: health.HealthStatusProgressing, wantState: "pending"},
+		{phase: synccommon.OperationSucceeded, health: health.HealthStatusHealthy, wantState: "success"},
+		{phase: synccommon.OperationSucceeded, health: health.HealthStatusDegraded, wantState: "failure"},
+		{phase: synccommon.OperationFailed, health: health.HealthStatusHealthy, wantState: "failure"},
+		{phase: synccommon.OperationError, health: health.HealthStatusHealthy, wantState: "failure"},
+	} {
+		t.Run(string(tc.phase)+"/"+string(tc.health), func(t *testing.T) {
+			app := argocdv1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app1"},
+				Status: argocdv1alpha1.ApplicationStatus{
+					OperationState: &argocdv1alpha1.OperationState{Phase: tc.phase},
+					Health:         argocdv1alpha1.AppHealthStatus{Status: tc.health},
+				},
+			}
+			got := generateCommitStatus(context.TODO(), app, "https://argocd.example.com", defaultDeploymentStatusStatesOnHealth, nil)
+			if got == nil {
+				t.Fatalf("generateCommitStatus wants non-nil but was nil")
+			}
+			if got.State != tc.wantState {
+				t.Errorf("State wants %s but was %s", tc.wantState, got.State)
+			}
+			if got.Context != "argocd/app1" {
+				t.Errorf("Context wants argocd/app1 but was %s", got.Context)
+			}
+			if got.TargetURL != "https://argocd.example.com/applications/app1" {
+				t.Errorf("TargetURL wants the application URL but was %s", got.TargetURL)
+			}
+		})
+	}
+
+	t.Run("overridden states on health", func(t *testing.T) {
+		states, err := ParseDeploymentStatusStatesOnHealth("Progressing=pending,Suspended=inactive")
+		if err != nil {
+			t.Fatalf("ParseDeploymentStatusStatesOnHealth error: %s", err)
+		}
+		for _, healthStatus := range []health.HealthStatusCode{health.HealthStatusProgressing, health.HealthStatusSuspended} {
+			app := argocdv1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app1"},
+				Status: argocdv1alpha1.ApplicationStatus{
+					OperationState: &argocdv1alpha1.OperationState{Phase: synccommon.OperationSucceeded},
+					Health:         argocdv1alpha1.AppHealthStatus{Status: healthStatus},
+				},
+			}
+			got := generateCommitStatus(context.TODO(), app, "https://argocd.example.com", states, nil)
+			if got == nil || got.State != "pending" {
+				t.Errorf("%s wants a pending commit status but was %+v", healthStatus, got)
+			}
+		}
+	})
+
+	t.Run("application outside the namespace of Argo CD", func(t *testing.T) {
+		t.Setenv("ARGOCD_NAMESPACE", "argocd")
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "team1"},
+			Status: argocdv1alpha1.ApplicationStatus{
+				OperationState: &argocdv1alpha1.OperationState{Phase: synccommon.OperationRunning},
+			},
+		}
+		got := generateCommitStatus(context.TODO(), app, "https://argocd.example.com", defaultDeploymentStatusStatesOnHealth, nil)
+		if got.Context != "argocd/team1/app1" {
+			t.Errorf("Context wants argocd/team1/app1 but was %s", got.Context)
+		}
+	})
+
+	t.Run("no sync operation", func(t *testing.T) {
+		got := generateCommitStatus(context.TODO(), argocdv1alpha1.Application{}, "https://argocd.example.com", defaultDeploymentStatusStatesOnHealth, nil)
+		if got != nil {
+			t.Errorf("generateCommitStatus wants nil but was %+v", got)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// compareRevisions returns the comparison between the previous and current revisions.
+// It returns nil if the previous revision is unknown or the comparison is not available.
+func (c client) compareRevisions(ctx context.Context, sourceRevision argocd.SourceRevision) *github.Comparison {
+	if sourceRevision.PreviousRevision == "" || sourceRevision.PreviousRevision == sourceRevision.Revision {
+		return nil
+	}
+	repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+	if repository == nil {
+		return nil
+	}
+	comparison, err := c.ghc.CompareRevisions(ctx, *repository, sourceRevision.PreviousRevision, sourceRevision.Revision)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Info("unable to compare the revisions", "error", err)
+		return nil
+	}
+	return comparison
+}
+
+// generateComparisonText returns a line of the compare link and commit count.
+// It returns an empty string if comparison is nil.
+func generateComparisonText(comparison *github.Comparison) string {
+	if comparison == nil {
+		return ""
+	}
+	return fmt.Sprintf("Changes: [`%s...%s`](%s) (%d commits)",
+		shortRevision(comparison.BaseRevision),
+		shortRevision(comparison.HeadRevision),
+		comparison.HTMLURL,
+		comparison.TotalCommits,
+	)
+}
+
+func shortRevision(revision string) string {
+	if len(revision) > 7 {
+		return revision[:7]
+	}
+	return revision
+}
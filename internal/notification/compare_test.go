@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+func Test_generateComparisonText(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if got := generateComparisonText(nil); got != "" {
+			t.Errorf("text wants empty but was %s", got)
+		}
+	})
+	t.Run("comparison", func(t *testing.T) {
+		got := generateComparisonText(&github.Comparison{
+			BaseRevision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			HeadRevision: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			HTMLURL:      "https://github.com/owner/repo/compare/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa...bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			TotalCommits: 3,
+		})
+		const want = "Changes: [`aaaaaaa...bbbbbbb`](https://github.com/owner/repo/compare/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa...bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb) (3 commits)"
+		if want != got {
+			t.Errorf("text wants %s but was %s", want, got)
+		}
+	})
+}
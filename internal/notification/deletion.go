@@ -9,11 +9,10 @@ import (
 	"github.com/int128/argocd-commenter/internal/github"
 )
 
-func (c client) CreateDeploymentStatusOnDeletion(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
-	deploymentURL := argocd.GetDeploymentURL(app)
-	deployment := github.ParseDeploymentURL(deploymentURL)
+func (c client) CreateDeploymentStatusOnDeletion(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error) {
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
 	if deployment == nil {
-		return nil
+		return "", nil
 	}
 	ds := &DeploymentStatus{
 		GitHubDeployment: *deployment,
@@ -22,9 +21,13 @@ func (c client) CreateDeploymentStatusOnDeletion(ctx context.Context, app argocd
 			State:  "inactive",
 		},
 	}
+	templates := c.loadTemplates(ctx, app.Namespace)
+	data := newTemplateData(app, argocdURL, argocd.SourceRevision{}, nil)
+	ds.GitHubDeploymentStatus.Description = trimDescription(
+		templates.renderOrDefault(ctx, TemplateKeyDeploymentStatusOnDeletion, data, func() string { return "" }))
 
 	if err := c.createDeploymentStatus(ctx, *ds); err != nil {
-		return fmt.Errorf("unable to create a deployment status: %w", err)
+		return "", fmt.Errorf("unable to create a deployment status: %w", err)
 	}
-	return nil
+	return ds.GitHubDeploymentStatus.State, nil
 }
@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// CreateDeployment creates a GitHub Deployment for the synced revision of the first GitHub source.
+// It returns the URL of the created deployment, or empty string if no environment is set.
+func (c client) CreateDeployment(ctx context.Context, app argocdv1alpha1.Application) (string, error) {
+	environment := argocd.GetDeploymentEnvironment(app)
+	if environment == "" {
+		return "", nil
+	}
+	sourceRevision, repository := getFirstGitHubSourceRevision(app)
+	if repository == nil {
+		return "", nil
+	}
+	deploymentURL, err := c.ghc.CreateDeployment(ctx, *repository, github.DeploymentRequest{
+		Ref:                   sourceRevision.Revision,
+		Environment:           environment,
+		Description:           fmt.Sprintf("Deploying %s by Argo CD", app.Name),
+		ProductionEnvironment: argocd.IsProductionEnvironment(app),
+		TransientEnvironment:  argocd.IsTransientEnvironment(app),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create a deployment for revision %s: %w", sourceRevision.Revision, err)
+	}
+	logr.FromContextOrDiscard(ctx).Info("Created a deployment", "deploymentURL", deploymentURL, "environment", environment)
+	return deploymentURL, nil
+}
+
+// GetDeploymentQuerySourceRevision returns the source revision of the repository in the deployment query.
+// It returns nil if the application has not been synced or no source is in the repository,
+// because the revision of another repository never matches a deployment.
+func GetDeploymentQuerySourceRevision(app argocdv1alpha1.Application, deploymentQuery string) *argocd.SourceRevision {
+	q := github.ParseDeploymentQuery(deploymentQuery)
+	if q == nil {
+		return nil
+	}
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		if repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL); repository != nil && *repository == q.Repository {
+			return &sourceRevision
+		}
+	}
+	return nil
+}
+
+// ResolveDeploymentURL returns the URL of the newest deployment matched to the query at the revision.
+// It returns empty string if the query is invalid or no deployment is found.
+func (c client) ResolveDeploymentURL(ctx context.Context, deploymentQuery, revision string) (string, error) {
+	q := github.ParseDeploymentQuery(deploymentQuery)
+	if q == nil {
+		return "", nil
+	}
+	deploymentURL, err := c.ghc.FindLatestDeploymentURL(ctx, *q, revision)
+	if err != nil {
+		return "", fmt.Errorf("unable to find a deployment of environment %s: %w", q.Environment, err)
+	}
+	return deploymentURL, nil
+}
@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+)
+
+func TestGetDeploymentQuerySourceRevision(t *testing.T) {
+	app := argocdv1alpha1.Application{
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Sources: argocdv1alpha1.ApplicationSources{
+				{RepoURL: "https://github.com/owner/manifests.git"},
+				{RepoURL: "https://github.com/owner/app.git"},
+			},
+		},
+		Status: argocdv1alpha1.ApplicationStatus{
+			OperationState: &argocdv1alpha1.OperationState{
+				Operation: argocdv1alpha1.Operation{
+					Sync: &argocdv1alpha1.SyncOperation{Revisions: []string{"sha1", "sha2"}},
+				},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "first source", query: "repo=owner/manifests,environment=pr-123", want: "sha1"},
+		{name: "second source", query: "repo=owner/app,environment=pr-123", want: "sha2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := GetDeploymentQuerySourceRevision(app, tc.query)
+			if got == nil {
+				t.Fatalf("GetDeploymentQuerySourceRevision wants %s but got nil", tc.want)
+			}
+			if got.Revision != tc.want {
+				t.Errorf("GetDeploymentQuerySourceRevision wants %s but got %s", tc.want, got.Revision)
+			}
+		})
+	}
+
+	t.Run("no source in the repository", func(t *testing.T) {
+		if got := GetDeploymentQuerySourceRevision(app, "repo=owner/other,environment=pr-123"); got != nil {
+			t.Errorf("GetDeploymentQuerySourceRevision wants nil but got %+v", got)
+		}
+	})
+
+	t.Run("not synced", func(t *testing.T) {
+		if got := GetDeploymentQuerySourceRevision(argocdv1alpha1.Application{}, "repo=owner/app,environment=pr-123"); got != nil {
+			t.Errorf("GetDeploymentQuerySourceRevision wants nil but got %+v", got)
+		}
+	})
+}
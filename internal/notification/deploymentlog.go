@@ -0,0 +1,219 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+const (
+	deploymentLogBeginMarker = "<!-- argocd-commenter:deployment-log -->"
+	deploymentLogEndMarker   = "<!-- /argocd-commenter:deployment-log -->"
+	deploymentLogTableHeader = "| Time | Application | Revision | Pull Requests | Sync | Health | Duration |\n" +
+		"|------|-------------|----------|---------------|------|--------|----------|"
+
+	// maxDeploymentLogEntries is the number of entries kept in the issue.
+	// The older entries are removed, because the maximum length of issue body is 65536.
+	maxDeploymentLogEntries = 100
+)
+
+func deploymentLogLabel(env string) string {
+	return fmt.Sprintf("deployment-log:%s", env)
+}
+
+// deploymentLogEntry represents a row of the deployment log.
+type deploymentLogEntry struct {
+	// Key identifies the sync operation of the application.
+	Key string
+	Row string
+}
+
+// UpdateDeploymentLog appends or updates the entry of the sync operation in the tracking issue of the environment.
+// The issue is found by the label "deployment-log:<env>", or created if not found.
+// The issue is in the repository of the annotation, or the repository of the first source by default.
+// It does nothing if the application has no environment.
+func (c client) UpdateDeploymentLog(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
+	env := argocd.GetEnvironment(app)
+	if env == "" || app.Status.OperationState == nil {
+		return nil
+	}
+	sourceRevision, sourceRepository := getFirstGitHubSourceRevision(app)
+	if sourceRepository == nil {
+		return nil
+	}
+	repository := sourceRepository
+	var pullRefPrefix string
+	if s := argocd.GetDeploymentLogRepository(app); s != "" {
+		repository = parseRepositoryName(s)
+		if repository == nil {
+			return fmt.Errorf("invalid deployment-log-repo annotation: %s", s)
+		}
+		if *repository != *sourceRepository {
+			// A pull request of another repository needs the full reference.
+			pullRefPrefix = fmt.Sprintf("%s/%s", sourceRepository.Owner, sourceRepository.Name)
+		}
+	}
+	logger := logr.FromContextOrDiscard(ctx).WithValues("environment", env, "repository", repository)
+
+	pulls, err := c.listPullRequests(ctx, *sourceRepository, sourceRevision)
+	if err != nil {
+		return fmt.Errorf("unable to list pull requests of revision %s: %w", sourceRevision.Revision, err)
+	}
+	relatedPulls := filterPullRequestsRelatedToEvent(pulls, sourceRevision, app)
+	entry := generateDeploymentLogEntry(app, argocdURL, sourceRevision, relatedPulls, pullRefPrefix)
+
+	// Multiple applications may update the same issue concurrently.
+	c.commentMu.Lock()
+	defer c.commentMu.Unlock()
+
+	label := deploymentLogLabel(env)
+	issue, err := c.ghc.FindOpenIssueByLabel(ctx, *repository, label)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		title := fmt.Sprintf("Deployments to %s", env)
+		body := generateDeploymentLogBody([]deploymentLogEntry{entry})
+		issue, err := c.ghc.CreateIssue(ctx, *repository, title, body, []string{label})
+		if err != nil {
+			return err
+		}
+		logger.Info("Created the tracking issue of the environment", "issueNumber", issue.Number)
+		return nil
+	}
+
+	entries := upsertDeploymentLogEntry(parseDeploymentLog(issue.Body), entry)
+	body := replaceDeploymentLog(issue.Body, entries)
+	if body == issue.Body {
+		logger.Info("Tracking issue is already up-to-date", "issueNumber", issue.Number)
+		return nil
+	}
+	if err := c.ghc.EditIssueBody(ctx, *repository, issue.Number, body); err != nil {
+		return err
+	}
+	logger.Info("Updated the tracking issue of the environment", "issueNumber", issue.Number)
+	return nil
+}
+
+func getFirstGitHubSourceRevision(app argocdv1alpha1.Application) (argocd.SourceRevision, *github.Repository) {
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		if repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL); repository != nil {
+			return sourceRevision, repository
+		}
+	}
+	return argocd.SourceRevision{}, nil
+}
+
+// parseRepositoryName parses the repository name such as owner/repo, or returns nil if invalid.
+func parseRepositoryName(s string) *github.Repository {
+	owner, name, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil
+	}
+	return &github.Repository{Owner: owner, Name: name}
+}
+
+func generateDeploymentLogEntry(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision,
+	pulls []github.PullRequest, pullRefPrefix string) deploymentLogEntry {
+	startedAt := app.Status.OperationState.StartedAt.UTC().Format(time.RFC3339)
+	key := fmt.Sprintf("%s/%s@%s", app.Namespace, app.Name, startedAt)
+
+	var pullRefs []string
+	for _, pull := range pulls {
+		pullRefs = append(pullRefs, fmt.Sprintf("%s#%d", pullRefPrefix, pull.Number))
+	}
+	var duration string
+	if finishedAt := argocd.GetSyncOperationFinishedAt(app); finishedAt != nil {
+		duration = finishedAt.Sub(app.Status.OperationState.StartedAt.Time).Round(time.Second).String()
+	}
+	row := fmt.Sprintf("| <!-- %s --> %s | [%s](%s/applications/%s) | `%s` | %s | %s | %s | %s |",
+		key,
+		startedAt,
+		app.Name, argocdURL, app.Name,
+		shortRevision(sourceRevision.Revision),
+		strings.Join(pullRefs, ", "),
+		app.Status.OperationState.Phase,
+		app.Status.Health.Status,
+		duration,
+	)
+	return deploymentLogEntry{Key: key, Row: row}
+}
+
+// parseDeploymentLog returns the entries in the issue body.
+func parseDeploymentLog(body string) []deploymentLogEntry {
+	_, afterBegin, ok := strings.Cut(body, deploymentLogBeginMarker)
+	if !ok {
+		return nil
+	}
+	section, _, _ := strings.Cut(afterBegin, deploymentLogEndMarker)
+	var entries []deploymentLogEntry
+	for line := range strings.Lines(section) {
+		line = strings.TrimSpace(line)
+		afterKeyBegin, ok := strings.CutPrefix(line, "| <!-- ")
+		if !ok {
+			continue
+		}
+		key, _, ok := strings.Cut(afterKeyBegin, " -->")
+		if !ok {
+			continue
+		}
+		entries = append(entries, deploymentLogEntry{Key: key, Row: line})
+	}
+	return entries
+}
+
+// upsertDeploymentLogEntry replaces the entry of the same key, or appends it.
+// It keeps the latest entries up to maxDeploymentLogEntries.
+func upsertDeploymentLogEntry(entries []deploymentLogEntry, entry deploymentLogEntry) []deploymentLogEntry {
+	replaced := false
+	for i := range entries {
+		if entries[i].Key == entry.Key {
+			entries[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	if len(entries) > maxDeploymentLogEntries {
+		entries = entries[len(entries)-maxDeploymentLogEntries:]
+	}
+	return entries
+}
+
+func generateDeploymentLogSection(entries []deploymentLogEntry) string {
+	var b strings.Builder
+	b.WriteString(deploymentLogBeginMarker)
+	b.WriteString("\n")
+	b.WriteString(deploymentLogTableHeader)
+	b.WriteString("\n")
+	for _, entry := range entries {
+		b.WriteString(entry.Row)
+		b.WriteString("\n")
+	}
+	b.WriteString(deploymentLogEndMarker)
+	return b.String()
+}
+
+func generateDeploymentLogBody(entries []deploymentLogEntry) string {
+	return "This issue is updated by argocd-commenter on every sync of the Applications in the environment.\n\n" +
+		generateDeploymentLogSection(entries)
+}
+
+// replaceDeploymentLog replaces the section of the deployment log in the issue body.
+// If the body has no section, it appends the section.
+func replaceDeploymentLog(body string, entries []deploymentLogEntry) string {
+	section := generateDeploymentLogSection(entries)
+	before, afterBegin, ok := strings.Cut(body, deploymentLogBeginMarker)
+	if !ok {
+		return body + "\n\n" + section
+	}
+	_, after, _ := strings.Cut(afterBegin, deploymentLogEndMarker)
+	return before + section + after
+}
@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateDeploymentLogEntry(t *testing.T) {
+	startedAt := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	finishedAt := metav1.NewTime(startedAt.Add(95 * time.Second))
+	app := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "argocd", Name: "app1"},
+		Status: argocdv1alpha1.ApplicationStatus{
+			OperationState: &argocdv1alpha1.OperationState{
+				Phase:      synccommon.OperationSucceeded,
+				StartedAt:  startedAt,
+				FinishedAt: &finishedAt,
+			},
+			Health: argocdv1alpha1.AppHealthStatus{Status: health.HealthStatusHealthy},
+		},
+	}
+	sourceRevision := argocd.SourceRevision{Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa101"}
+	pulls := []github.PullRequest{{Number: 101}, {Number: 102}}
+	got := generateDeploymentLogEntry(app, "https://argocd.example.com", sourceRevision, pulls, "")
+	want := deploymentLogEntry{
+		Key: "argocd/app1@2026-01-02T03:04:05Z",
+		Row: "| <!-- argocd/app1@2026-01-02T03:04:05Z --> 2026-01-02T03:04:05Z | " +
+			"[app1](https://argocd.example.com/applications/app1) | `aaaaaaa` | #101, #102 | Succeeded | Healthy | 1m35s |",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("tracking issue in another repository", func(t *testing.T) {
+		got := generateDeploymentLogEntry(app, "https://argocd.example.com", sourceRevision, pulls, "owner/manifests")
+		const wantPullRefs = "| owner/manifests#101, owner/manifests#102 |"
+		if !strings.Contains(got.Row, wantPullRefs) {
+			t.Errorf("row wants %s but was %s", wantPullRefs, got.Row)
+		}
+	})
+}
+
+func Test_parseRepositoryName(t *testing.T) {
+	if diff := cmp.Diff(&github.Repository{Owner: "owner", Name: "repo"}, parseRepositoryName("owner/repo")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	for _, s := range []string{"", "owner", "owner/", "/repo", "owner/repo/extra"} {
+		if got := parseRepositoryName(s); got != nil {
+			t.Errorf("parseRepositoryName(%q) wants nil but was %+v", s, got)
+		}
+	}
+}
+
+func Test_replaceDeploymentLog(t *testing.T) {
+	entry1 := deploymentLogEntry{Key: "argocd/app1@1", Row: "| <!-- argocd/app1@1 --> 1 | Running |"}
+	entry1Updated := deploymentLogEntry{Key: "argocd/app1@1", Row: "| <!-- argocd/app1@1 --> 1 | Succeeded |"}
+	entry2 := deploymentLogEntry{Key: "argocd/app2@2", Row: "| <!-- argocd/app2@2 --> 2 | Running |"}
+
+	body := generateDeploymentLogBody([]deploymentLogEntry{entry1})
+	body = "Edited by a user\n\n" + body
+
+	t.Run("update the entry", func(t *testing.T) {
+		entries := upsertDeploymentLogEntry(parseDeploymentLog(body), entry1Updated)
+		got := parseDeploymentLog(replaceDeploymentLog(body, entries))
+		if diff := cmp.Diff([]deploymentLogEntry{entry1Updated}, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("append an entry", func(t *testing.T) {
+		entries := upsertDeploymentLogEntry(parseDeploymentLog(body), entry2)
+		newBody := replaceDeploymentLog(body, entries)
+		if diff := cmp.Diff([]deploymentLogEntry{entry1, entry2}, parseDeploymentLog(newBody)); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+		const wantPrefix = "Edited by a user\n\n"
+		if newBody[:len(wantPrefix)] != wantPrefix {
+			t.Errorf("body wants to keep the prefix but was %s", newBody)
+		}
+	})
+
+	t.Run("keep the latest entries", func(t *testing.T) {
+		var entries []deploymentLogEntry
+		for i := range maxDeploymentLogEntries + 10 {
+			key := fmt.Sprintf("argocd/app1@%d", i)
+			entries = upsertDeploymentLogEntry(entries, deploymentLogEntry{Key: key, Row: fmt.Sprintf("| <!-- %s --> |", key)})
+		}
+		if len(entries) != maxDeploymentLogEntries {
+			t.Fatalf("len(entries) wants %d but was %d", maxDeploymentLogEntries, len(entries))
+		}
+		if want := "argocd/app1@10"; entries[0].Key != want {
+			t.Errorf("entries[0].Key wants %s but was %s", want, entries[0].Key)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// IsValidDispatch returns true if the dispatch annotation is valid.
+func IsValidDispatch(s string) bool {
+	return github.ParseDispatch(s) != nil
+}
+
+// CreateDispatch sends a repository_dispatch or workflow_dispatch event to the target in the annotation.
+// It does nothing if the annotation is not set.
+// If the related pull requests could not be loaded, it sends the event without the pull request numbers,
+// because the subsequent workflow should run regardless of them.
+func (c client) CreateDispatch(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	annotation := argocd.GetDispatch(app)
+	if annotation == "" {
+		return nil
+	}
+	dispatch := github.ParseDispatch(annotation)
+	if dispatch == nil {
+		return fmt.Errorf("invalid dispatch annotation: %s", annotation)
+	}
+	relatedPullsOfSources, err := loadRelatedPulls()
+	if err != nil {
+		logger.Info("unable to load the related pull requests, dispatch without them", "error", err)
+	}
+	payload := generateDispatchPayload(app, relatedPullsOfSources)
+	if err := c.ghc.CreateDispatch(ctx, *dispatch, payload); err != nil {
+		return err
+	}
+	logger.Info("Created a dispatch event", "dispatch", dispatch)
+	return nil
+}
+
+func generateDispatchPayload(app argocdv1alpha1.Application, relatedPullsOfSources []RelatedPullRequests) github.DispatchPayload {
+	payload := github.DispatchPayload{
+		Application:    app.Name,
+		Namespace:      app.Namespace,
+		EnvironmentURL: argocd.GetApplicationExternalURL(app),
+	}
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		payload.Revisions = append(payload.Revisions, sourceRevision.Revision)
+	}
+	for _, relatedPulls := range relatedPullsOfSources {
+		for _, pull := range relatedPulls.PullRequests {
+			if !slices.Contains(payload.PullRequests, pull.Number) {
+				payload.PullRequests = append(payload.PullRequests, pull.Number)
+			}
+		}
+	}
+	return payload
+}
@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateDispatch(t *testing.T) {
+	app := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "argocd",
+			Annotations: map[string]string{
+				"argocd-commenter.int128.github.io/dispatch": "repo=owner/repo,event-type=smoke-test",
+			},
+		},
+	}
+
+	t.Run("without the pull requests if unable to load them", func(t *testing.T) {
+		var sv githubmock.Server
+		var bodies []string
+		sv.Handle("POST /api/v3/repos/owner/repo/dispatches", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("could not read the request body: %s", err)
+			}
+			bodies = append(bodies, string(body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		c := newMockClient(t, &sv)
+
+		loadRelatedPulls := func() ([]RelatedPullRequests, error) { return nil, errors.New("rate limit exceeded") }
+		if err := c.CreateDispatch(context.TODO(), app, loadRelatedPulls); err != nil {
+			t.Fatalf("CreateDispatch error: %s", err)
+		}
+		want := []string{`{"event_type":"smoke-test","client_payload":{"application":"app","namespace":"argocd","revisions":null,"pull_requests":null}}` + "\n"}
+		if diff := cmp.Diff(want, bodies); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestIsValidDispatch(t *testing.T) {
+	if !IsValidDispatch("repo=owner/repo,event-type=smoke-test") {
+		t.Errorf("IsValidDispatch wants true")
+	}
+	if IsValidDispatch("owner/repo") {
+		t.Errorf("IsValidDispatch wants false")
+	}
+}
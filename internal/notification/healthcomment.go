@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
@@ -19,9 +20,12 @@ var HealthStatusesForComment = []health.HealthStatusCode{
 
 func (c client) CreateCommentsOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
 	var errs []error
+	templates := c.loadTemplates(ctx, app.Namespace)
 	sourceRevisions := argocd.GetSourceRevisions(app)
 	for _, sourceRevision := range sourceRevisions {
-		comment := generateCommentOnHealthChanged(app, argocdURL, sourceRevision)
+		comparison := c.compareRevisions(ctx, sourceRevision)
+		sourceRevision = resolveRollback(sourceRevision, comparison)
+		comment := generateCommentOnHealthChanged(ctx, app, argocdURL, sourceRevision, comparison, templates)
 		if comment == nil {
 			continue
 		}
@@ -32,12 +36,16 @@ func (c client) CreateCommentsOnHealthChanged(ctx context.Context, app argocdv1a
 	return errors.Join(errs...)
 }
 
-func generateCommentOnHealthChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision) *Comment {
+func generateCommentOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison, templates *Templates) *Comment {
 	repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
 	if repository == nil {
 		return nil
 	}
-	body := generateCommentBodyOnHealthChanged(app, argocdURL, sourceRevision)
+	body := generateCommentBodyOnHealthChanged(ctx, app, argocdURL, sourceRevision, comparison, templates)
+	if body != "" && sourceRevision.Rollback {
+		body = generateCommentBodyOnRollback(ctx, app, argocdURL, sourceRevision, string(app.Status.Health.Status),
+			getFailedResourcesOnHealthChanged(app), app.Status.Health.Status == health.HealthStatusDegraded, templates)
+	}
 	if body == "" {
 		return nil
 	}
@@ -45,26 +53,65 @@ func generateCommentOnHealthChanged(app argocdv1alpha1.Application, argocdURL st
 		GitHubRepository: *repository,
 		SourceRevision:   sourceRevision,
 		Body:             body,
+		Time:             getHealthStatusTransitionTime(app),
+		Failed:           app.Status.Health.Status == health.HealthStatusDegraded,
+		Reaction:         reactionsOnHealth[app.Status.Health.Status],
+		Comparison:       comparison,
+	}
+}
+
+func getHealthStatusTransitionTime(app argocdv1alpha1.Application) time.Time {
+	if app.Status.Health.LastTransitionTime == nil {
+		return time.Now()
+	}
+	return app.Status.Health.LastTransitionTime.Time
+}
+
+var reactionsOnHealth = map[health.HealthStatusCode]string{
+	health.HealthStatusHealthy:  github.ReactionRocket,
+	health.HealthStatusDegraded: github.ReactionConfused,
+}
+
+var commentTemplateKeysOnHealth = map[health.HealthStatusCode]string{
+	health.HealthStatusHealthy:  TemplateKeyCommentOnHealthHealthy,
+	health.HealthStatusDegraded: TemplateKeyCommentOnHealthDegraded,
+}
+
+func generateCommentBodyOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison, templates *Templates) string {
+	key, ok := commentTemplateKeysOnHealth[app.Status.Health.Status]
+	if !ok {
+		return ""
 	}
+	data := newTemplateData(app, argocdURL, sourceRevision, getFailedResourcesOnHealthChanged(app))
+	data.Comparison = comparison
+	return templates.renderOrDefault(ctx, key, data, func() string {
+		return generateBuiltinCommentBodyOnHealthChanged(app, argocdURL, sourceRevision, comparison)
+	})
 }
 
-func generateCommentBodyOnHealthChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision) string {
+func generateBuiltinCommentBodyOnHealthChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison) string {
 	argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, app.Name)
 	switch app.Status.Health.Status {
 	case health.HealthStatusHealthy:
-		return fmt.Sprintf(":white_check_mark: %s [%s](%s) at %s",
-			app.Status.Health.Status,
-			app.Name,
-			argocdApplicationURL,
-			sourceRevision.Revision,
+		return appendSection(
+			fmt.Sprintf(":white_check_mark: %s [%s](%s) at %s",
+				app.Status.Health.Status,
+				app.Name,
+				argocdApplicationURL,
+				sourceRevision.Revision,
+			),
+			generateComparisonText(comparison),
 		)
 	case health.HealthStatusDegraded:
-		return fmt.Sprintf("## :x: %s [%s](%s) at %s:\n%s",
-			app.Status.Health.Status,
-			app.Name,
-			argocdApplicationURL,
-			sourceRevision.Revision,
-			generateCommentResourcesOnHealthChanged(app),
+		return appendSection(
+			fmt.Sprintf("## :x: %s [%s](%s) at %s:\n%s",
+				app.Status.Health.Status,
+				app.Name,
+				argocdApplicationURL,
+				sourceRevision.Revision,
+				generateCommentResourcesOnHealthChanged(app),
+			),
+			generateComparisonText(comparison),
 		)
 	}
 	return ""
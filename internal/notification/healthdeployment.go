@@ -3,6 +3,8 @@ package notification
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
@@ -14,57 +16,120 @@ import (
 var HealthStatusesForDeploymentStatus = []health.HealthStatusCode{
 	health.HealthStatusHealthy,
 	health.HealthStatusDegraded,
+	health.HealthStatusProgressing,
+	health.HealthStatusSuspended,
 }
 
-func (c client) CreateDeploymentStatusOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
-	ds := generateDeploymentStatusOnHealthChanged(app, argocdURL)
+func (c client) CreateDeploymentStatusOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error) {
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
+	if deployment == nil {
+		return "", nil
+	}
+	templates := c.loadTemplates(ctx, app.Namespace)
+	ds := generateDeploymentStatusOnHealthChanged(ctx, app, argocdURL, *deployment, c.getDeploymentStatusStatesOnHealth(), templates)
 	if ds == nil {
-		return nil
+		return "", nil
 	}
 	if err := c.createDeploymentStatus(ctx, *ds); err != nil {
-		return fmt.Errorf("unable to create a deployment status: %w", err)
+		return "", fmt.Errorf("unable to create a deployment status: %w", err)
 	}
-	return nil
+	return ds.GitHubDeploymentStatus.State, nil
 }
 
-func generateDeploymentStatusOnHealthChanged(app argocdv1alpha1.Application, argocdURL string) *DeploymentStatus {
-	deploymentURL := argocd.GetDeploymentURL(app)
-	deployment := github.ParseDeploymentURL(deploymentURL)
-	if deployment == nil {
-		return nil
+var defaultDeploymentStatusStatesOnHealth = map[health.HealthStatusCode]string{
+	health.HealthStatusHealthy:     "success",
+	health.HealthStatusDegraded:    "failure",
+	health.HealthStatusProgressing: "in_progress",
+	health.HealthStatusSuspended:   "queued",
+}
+
+// validDeploymentStatusStates are the states accepted by GitHub API.
+// https://docs.github.com/en/rest/deployments/statuses#create-a-deployment-status
+var validDeploymentStatusStates = []string{
+	"error", "failure", "inactive", "in_progress", "queued", "pending", "success",
+}
+
+var deploymentStatusTemplateKeysOnHealth = map[health.HealthStatusCode]string{
+	health.HealthStatusHealthy:     TemplateKeyDeploymentStatusOnHealthHealthy,
+	health.HealthStatusDegraded:    TemplateKeyDeploymentStatusOnHealthDegraded,
+	health.HealthStatusProgressing: TemplateKeyDeploymentStatusOnHealthProgressing,
+	health.HealthStatusSuspended:   TemplateKeyDeploymentStatusOnHealthSuspended,
+}
+
+// ParseDeploymentStatusStatesOnHealth returns the state of deployment status for each health status.
+// The default mapping can be overridden by the environment variable DEPLOYMENT_STATUS_STATES_ON_HEALTH,
+// such as "Progressing=pending,Suspended=".
+// If the state is empty, no deployment status is created on the health status.
+// Healthy must be success and the others must not be success,
+// because the controller determines whether a deployment is healthy by the success state.
+func ParseDeploymentStatusStatesOnHealth(s string) (map[health.HealthStatusCode]string, error) {
+	states := maps.Clone(defaultDeploymentStatusStatesOnHealth)
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry must be HEALTH=STATE but was %s", entry)
+		}
+		healthStatus, state := health.HealthStatusCode(strings.TrimSpace(k)), strings.TrimSpace(v)
+		if !slices.Contains(HealthStatusesForDeploymentStatus, healthStatus) {
+			return nil, fmt.Errorf("health status must be one of %v but was %s", HealthStatusesForDeploymentStatus, healthStatus)
+		}
+		if healthStatus == health.HealthStatusHealthy && state != "success" {
+			return nil, fmt.Errorf("state of %s must be success but was %s", healthStatus, state)
+		}
+		if healthStatus != health.HealthStatusHealthy && state == "success" {
+			return nil, fmt.Errorf("state of %s must not be success", healthStatus)
+		}
+		if state == "" {
+			delete(states, healthStatus)
+			continue
+		}
+		if !slices.Contains(validDeploymentStatusStates, state) {
+			return nil, fmt.Errorf("state must be one of %v but was %s", validDeploymentStatusStates, state)
+		}
+		states[healthStatus] = state
 	}
+	return states, nil
+}
 
+func generateDeploymentStatusOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, deployment github.Deployment,
+	states map[health.HealthStatusCode]string, templates *Templates) *DeploymentStatus {
 	ds := DeploymentStatus{
-		GitHubDeployment: *deployment,
+		GitHubDeployment: deployment,
 		GitHubDeploymentStatus: github.DeploymentStatus{
 			LogURL:         fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
-			Description:    trimDescription(generateDeploymentStatusDescriptionOnHealthChanged(app)),
+			Description:    trimDescription(generateDeploymentStatusDescriptionOnHealthChanged(ctx, app, argocdURL, templates)),
 			EnvironmentURL: argocd.GetApplicationExternalURL(app),
 		},
 	}
-	switch app.Status.Health.Status {
-	case health.HealthStatusHealthy:
-		ds.GitHubDeploymentStatus.State = "success"
-		return &ds
-	case health.HealthStatusDegraded:
-		ds.GitHubDeploymentStatus.State = "failure"
-		return &ds
+	state, ok := states[app.Status.Health.Status]
+	if !ok {
+		return nil
+	}
+	ds.GitHubDeploymentStatus.State = state
+	return &ds
+}
+
+func generateDeploymentStatusDescriptionOnHealthChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, templates *Templates) string {
+	key, ok := deploymentStatusTemplateKeysOnHealth[app.Status.Health.Status]
+	if !ok {
+		return ""
 	}
-	return nil
+	data := newTemplateData(app, argocdURL, argocd.SourceRevision{}, getFailedResourcesOnHealthChanged(app))
+	return templates.renderOrDefault(ctx, key, data, func() string {
+		return generateBuiltinDeploymentStatusDescriptionOnHealthChanged(app)
+	})
 }
 
-func generateDeploymentStatusDescriptionOnHealthChanged(app argocdv1alpha1.Application) string {
+func generateBuiltinDeploymentStatusDescriptionOnHealthChanged(app argocdv1alpha1.Application) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "%s:\n", app.Status.Health.Status)
-	for _, r := range app.Status.Resources {
-		if r.Health == nil {
-			continue
-		}
+	for _, r := range getFailedResourcesOnHealthChanged(app) {
 		namespacedName := r.Namespace + "/" + r.Name
-		switch r.Health.Status {
-		case health.HealthStatusDegraded, health.HealthStatusMissing:
-			fmt.Fprintf(&b, "%s: %s: %s\n", namespacedName, r.Health.Status, r.Health.Message)
-		}
+		fmt.Fprintf(&b, "%s: %s: %s\n", namespacedName, r.Status, r.Message)
 	}
 	return b.String()
 }
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/int128/argocd-commenter/internal/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ParseDeploymentStatusStatesOnHealth(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		got, err := ParseDeploymentStatusStatesOnHealth("")
+		if err != nil {
+			t.Fatalf("ParseDeploymentStatusStatesOnHealth error: %s", err)
+		}
+		if !maps.Equal(got, defaultDeploymentStatusStatesOnHealth) {
+			t.Errorf("states wants the default but was %v", got)
+		}
+	})
+	t.Run("Override and disable", func(t *testing.T) {
+		got, err := ParseDeploymentStatusStatesOnHealth("Progressing=pending, Suspended=")
+		if err != nil {
+			t.Fatalf("ParseDeploymentStatusStatesOnHealth error: %s", err)
+		}
+		want := map[health.HealthStatusCode]string{
+			health.HealthStatusHealthy:     "success",
+			health.HealthStatusDegraded:    "failure",
+			health.HealthStatusProgressing: "pending",
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("states wants %v but was %v", want, got)
+		}
+	})
+	for _, s := range []string{"Progressing", "Missing=failure", "Degraded=done", "Healthy=", "Healthy=pending", "Progressing=success"} {
+		t.Run("Invalid "+s, func(t *testing.T) {
+			_, err := ParseDeploymentStatusStatesOnHealth(s)
+			if err == nil {
+				t.Errorf("ParseDeploymentStatusStatesOnHealth wants error but was nil")
+			}
+		})
+	}
+}
+
+func Test_generateDeploymentStatusOnHealthChanged(t *testing.T) {
+	app := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1"},
+		Status: argocdv1alpha1.ApplicationStatus{
+			Health: argocdv1alpha1.AppHealthStatus{Status: health.HealthStatusProgressing},
+			Resources: []argocdv1alpha1.ResourceStatus{
+				{
+					Namespace: "default",
+					Name:      "server",
+					Health: &argocdv1alpha1.HealthStatus{
+						Status:  health.HealthStatusProgressing,
+						Message: "Waiting for rollout to finish",
+					},
+				},
+				{
+					Namespace: "default",
+					Name:      "worker",
+					Health:    &argocdv1alpha1.HealthStatus{Status: health.HealthStatusHealthy},
+				},
+			},
+		},
+	}
+	deployment := github.Deployment{Repository: github.Repository{Owner: "int128", Name: "sandbox"}, Id: 1}
+	ds := generateDeploymentStatusOnHealthChanged(context.TODO(), app, "https://argocd.example.com", deployment, defaultDeploymentStatusStatesOnHealth, nil)
+	if ds == nil {
+		t.Fatalf("generateDeploymentStatusOnHealthChanged wants non-nil but was nil")
+	}
+	if want := "in_progress"; ds.GitHubDeploymentStatus.State != want {
+		t.Errorf("State wants %s but was %s", want, ds.GitHubDeploymentStatus.State)
+	}
+	wantDescription := "Progressing:\ndefault/server: Progressing: Waiting for rollout to finish\n"
+	if ds.GitHubDeploymentStatus.Description != wantDescription {
+		t.Errorf("Description wants %q but was %q", wantDescription, ds.GitHubDeploymentStatus.Description)
+	}
+}
@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// closingIssue represents an issue closed by the related pull requests.
+type closingIssue struct {
+	Issue        github.Issue
+	PullRequests []string
+}
+
+// NotifyClosingIssues notifies the deployment to the issues closed by the related pull requests.
+// It creates or updates a comment of the application on each issue,
+// and adds the label "deployed:<env>" if the application has the environment.
+// It does nothing in the reaction mode or unless the application is healthy.
+func (c client) NotifyClosingIssues(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, loadRelatedPulls RelatedPullRequestsLoader) error {
+	if os.Getenv("FEATURE_NOTIFY_CLOSING_ISSUES") != "true" {
+		return nil
+	}
+	if getNotificationMode(app) == NotificationModeReaction {
+		return nil
+	}
+	if app.Status.Health.Status != health.HealthStatusHealthy {
+		return nil
+	}
+	relatedPullsOfSources, err := loadRelatedPulls()
+	errs := []error{err}
+	for _, relatedPulls := range relatedPullsOfSources {
+		issues, err := c.listClosingIssues(ctx, relatedPulls.Repository, relatedPulls.PullRequests)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, issue := range issues {
+			if err := c.notifyClosingIssue(ctx, app, argocdURL, relatedPulls.SourceRevision, issue); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listClosingIssues returns the issues closed by the pull requests without duplicates.
+func (c client) listClosingIssues(ctx context.Context, repository github.Repository, pulls []github.PullRequest) ([]closingIssue, error) {
+	var closingIssues []closingIssue
+	var errs []error
+	for _, pull := range pulls {
+		issues, err := c.ghc.ListClosingIssues(ctx, repository, pull.Number)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pullRef := fmt.Sprintf("%s/%s#%d", repository.Owner, repository.Name, pull.Number)
+		for _, issue := range issues {
+			i := slices.IndexFunc(closingIssues, func(ci closingIssue) bool { return ci.Issue == issue })
+			if i < 0 {
+				closingIssues = append(closingIssues, closingIssue{Issue: issue})
+				i = len(closingIssues) - 1
+			}
+			closingIssues[i].PullRequests = append(closingIssues[i].PullRequests, pullRef)
+		}
+	}
+	return closingIssues, errors.Join(errs...)
+}
+
+func (c client) notifyClosingIssue(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, issue closingIssue) error {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("issue", issue.Issue)
+	// An issue shares the comments API with a pull request.
+	marker := fmt.Sprintf("<!-- argocd-commenter:issue-deployment=%s/%s -->", app.Namespace, app.Name)
+	body := generateClosingIssueCommentBody(marker, app, argocdURL, sourceRevision, issue)
+	if err := c.upsertPullRequestComment(ctx, issue.Issue.Repository, issue.Issue.Number, marker,
+		func(*github.IssueComment) string { return body }); err != nil {
+		return fmt.Errorf("unable to comment on the issue #%d: %w", issue.Issue.Number, err)
+	}
+
+	env := argocd.GetEnvironment(app)
+	if env == "" {
+		return nil
+	}
+	if err := c.ghc.AddLabelsToPullRequest(ctx, issue.Issue.Repository, issue.Issue.Number, []string{deployedLabel(env)}); err != nil {
+		return fmt.Errorf("unable to label the issue #%d: %w", issue.Issue.Number, err)
+	}
+	logger.Info("Added the label to the issue", "label", deployedLabel(env))
+	return nil
+}
+
+func generateClosingIssueCommentBody(marker string, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, issue closingIssue) string {
+	argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, app.Name)
+	return fmt.Sprintf("%s\n:rocket: Deployed [%s](%s) at %s by %s",
+		marker,
+		app.Name,
+		argocdApplicationURL,
+		sourceRevision.Revision,
+		strings.Join(issue.PullRequests, ", "),
+	)
+}
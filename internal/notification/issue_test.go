@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateClosingIssueCommentBody(t *testing.T) {
+	app := argocdv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "argocd", Name: "app1"}}
+	sourceRevision := argocd.SourceRevision{Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa101"}
+	issue := closingIssue{
+		Issue:        github.Issue{Repository: github.Repository{Owner: "owner", Name: "repo"}, Number: 123},
+		PullRequests: []string{"owner/repo#101", "owner/repo#102"},
+	}
+	got := generateClosingIssueCommentBody("<!-- marker -->", app, "https://argocd.example.com", sourceRevision, issue)
+	const want = "<!-- marker -->\n" +
+		":rocket: Deployed [app1](https://argocd.example.com/applications/app1) at aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa101 by owner/repo#101, owner/repo#102"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,129 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+func deployedLabel(env string) string {
+	return fmt.Sprintf("deployed:%s", env)
+}
+
+func degradedLabel(env string) string {
+	return fmt.Sprintf("degraded:%s", env)
+}
+
+// UpdateDeploymentLabels labels the related pull requests with the environment of the application.
+// When the application is healthy, it adds "deployed:<env>" and removes "degraded:<env>".
+// When the application is degraded, it adds "degraded:<env>" and removes "deployed:<env>".
+// It does nothing if the application has no environment.
+func (c client) UpdateDeploymentLabels(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error {
+	env := argocd.GetEnvironment(app)
+	if env == "" {
+		return nil
+	}
+	var labelToAdd, labelToRemove string
+	switch app.Status.Health.Status {
+	case health.HealthStatusHealthy:
+		labelToAdd, labelToRemove = deployedLabel(env), degradedLabel(env)
+	case health.HealthStatusDegraded:
+		labelToAdd, labelToRemove = degradedLabel(env), deployedLabel(env)
+	default:
+		return nil
+	}
+
+	relatedPullsOfSources, err := loadRelatedPulls()
+	errs := []error{err}
+	for _, relatedPulls := range relatedPullsOfSources {
+		if err := c.updatePullRequestLabels(ctx, relatedPulls.Repository, relatedPulls.PullRequests, labelToAdd, labelToRemove); err != nil {
+			errs = append(errs, err)
+		}
+		if app.Status.Health.Status == health.HealthStatusHealthy {
+			// A healthy deployment supersedes the degraded state of the earlier pull requests.
+			if err := c.removeSupersededLabel(ctx, app, relatedPulls, labelToRemove); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c client) updatePullRequestLabels(ctx context.Context, repository github.Repository, pulls []github.PullRequest, labelToAdd, labelToRemove string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	var errs []error
+	for _, pull := range pulls {
+		if !slices.Contains(pull.Labels, labelToAdd) {
+			if err := c.ghc.AddLabelsToPullRequest(ctx, repository, pull.Number, []string{labelToAdd}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			logger.Info("Added the label to the pull request", "pullNumber", pull.Number, "label", labelToAdd)
+		}
+		if slices.Contains(pull.Labels, labelToRemove) {
+			if err := c.ghc.RemoveLabelFromPullRequest(ctx, repository, pull.Number, labelToRemove); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			logger.Info("Removed the label from the pull request", "pullNumber", pull.Number, "label", labelToRemove)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// maxSupersededLabelPullRequests is the maximum number of the labeled pull requests to look up at once.
+// This avoids too many API calls when the label remains on many pull requests.
+const maxSupersededLabelPullRequests = 10
+
+// removeSupersededLabel removes the label from the earlier pull requests superseded by the current deployment.
+// It looks up only the recently updated pull requests, because a merged pull request is closed when merged.
+// The other pull requests are left as-is, because they may be labeled by another application of the same environment.
+func (c client) removeSupersededLabel(ctx context.Context, app argocdv1alpha1.Application, relatedPulls RelatedPullRequests, label string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	repository := relatedPulls.Repository
+	pullNumbers, err := c.ghc.ListPullRequestNumbersByLabel(ctx, repository, label, maxSupersededLabelPullRequests)
+	if err != nil {
+		return err
+	}
+	manifestGeneratePaths := getManifestGeneratePaths(app)
+	var errs []error
+	for _, pullNumber := range pullNumbers {
+		if slices.ContainsFunc(relatedPulls.PullRequests, func(pull github.PullRequest) bool { return pull.Number == pullNumber }) {
+			continue
+		}
+		pull, err := c.ghc.GetPullRequest(ctx, repository, pullNumber)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if pull.MergeCommitSHA == "" || !isPullRequestRelatedToEvent(*pull, relatedPulls.SourceRevision, manifestGeneratePaths) {
+			continue
+		}
+		status, err := c.ghc.GetComparisonStatus(ctx, repository, pull.MergeCommitSHA, relatedPulls.SourceRevision.Revision)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !isAncestorComparisonStatus(status) {
+			continue
+		}
+		if err := c.ghc.RemoveLabelFromPullRequest(ctx, repository, pullNumber, label); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Removed the superseded label from the pull request", "pullNumber", pullNumber, "label", label)
+	}
+	return errors.Join(errs...)
+}
+
+// isAncestorComparisonStatus returns true if the base is an ancestor of the head, or the same commit.
+func isAncestorComparisonStatus(status string) bool {
+	return status == github.ComparisonStatusAhead || status == github.ComparisonStatusIdentical
+}
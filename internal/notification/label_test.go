@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/google/go-cmp/cmp"
+	gogithub "github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateDeploymentLabels(t *testing.T) {
+	repository := github.Repository{Owner: "owner", Name: "repo"}
+	app := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Annotations: map[string]string{"argocd-commenter.int128.github.io/environment": "staging"},
+		},
+		Status: argocdv1alpha1.ApplicationStatus{
+			Health: argocdv1alpha1.AppHealthStatus{Status: health.HealthStatusHealthy},
+		},
+	}
+	sourceRevision := argocd.SourceRevision{
+		Source:   argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/owner/repo.git", Path: "app"},
+		Revision: "head",
+	}
+	loadRelatedPulls := func() ([]RelatedPullRequests, error) {
+		return []RelatedPullRequests{{
+			Repository:     repository,
+			SourceRevision: sourceRevision,
+			PullRequests:   []github.PullRequest{{Number: 1, Labels: []string{"degraded:staging"}}},
+		}}, nil
+	}
+	newMergedPull := func(number int, mergeCommitSHA string) *gogithub.PullRequest {
+		return &gogithub.PullRequest{
+			Number:         gogithub.Ptr(number),
+			MergedAt:       &gogithub.Timestamp{},
+			MergeCommitSHA: gogithub.Ptr(mergeCommitSHA),
+		}
+	}
+	pullRequestLinks := &gogithub.PullRequestLinks{}
+
+	var sv githubmock.Server
+	var requests []string
+	sv.Handle("POST /api/v3/repos/owner/repo/issues/1/labels", recordRequests(&requests))
+	sv.Handle("DELETE /api/v3/repos/owner/repo/issues/1/labels/degraded:staging", recordRequests(&requests))
+	sv.Handle("GET /api/v3/repos/owner/repo/issues?direction=desc&labels=degraded%3Astaging&per_page=10&sort=updated&state=closed", respondJSON(t, []*gogithub.Issue{
+		{Number: gogithub.Ptr(1), PullRequestLinks: pullRequestLinks},
+		{Number: gogithub.Ptr(2), PullRequestLinks: pullRequestLinks},
+		{Number: gogithub.Ptr(3), PullRequestLinks: pullRequestLinks},
+		{Number: gogithub.Ptr(4), PullRequestLinks: pullRequestLinks},
+		{Number: gogithub.Ptr(5), PullRequestLinks: pullRequestLinks},
+	}))
+	// #2 is merged before the current revision
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/2", respondJSON(t, newMergedPull(2, "merge2")))
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/2/files", respondJSON(t, []*gogithub.CommitFile{{Filename: gogithub.Ptr("app/a.yaml")}}))
+	sv.Handle("GET /api/v3/repos/owner/repo/compare/merge2...head?per_page=1", respondJSON(t, gogithub.CommitsComparison{Status: gogithub.Ptr("ahead")}))
+	sv.Handle("DELETE /api/v3/repos/owner/repo/issues/2/labels/degraded:staging", recordRequests(&requests))
+	// #3 is not related to the application
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/3", respondJSON(t, newMergedPull(3, "merge3")))
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/3/files", respondJSON(t, []*gogithub.CommitFile{{Filename: gogithub.Ptr("other/a.yaml")}}))
+	// #4 is not deployed yet
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/4", respondJSON(t, newMergedPull(4, "merge4")))
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/4/files", respondJSON(t, []*gogithub.CommitFile{{Filename: gogithub.Ptr("app/b.yaml")}}))
+	sv.Handle("GET /api/v3/repos/owner/repo/compare/merge4...head?per_page=1", respondJSON(t, gogithub.CommitsComparison{Status: gogithub.Ptr("diverged")}))
+	// #5 is not merged
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/5", respondJSON(t, &gogithub.PullRequest{Number: gogithub.Ptr(5)}))
+	sv.Handle("GET /api/v3/repos/owner/repo/pulls/5/files", respondJSON(t, []*gogithub.CommitFile{{Filename: gogithub.Ptr("app/c.yaml")}}))
+
+	c := newMockClient(t, &sv)
+	if err := c.UpdateDeploymentLabels(context.TODO(), app, loadRelatedPulls); err != nil {
+		t.Fatalf("UpdateDeploymentLabels error: %s", err)
+	}
+	want := []string{
+		"POST /api/v3/repos/owner/repo/issues/1/labels",
+		"DELETE /api/v3/repos/owner/repo/issues/1/labels/degraded:staging",
+		"DELETE /api/v3/repos/owner/repo/issues/2/labels/degraded:staging",
+	}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+}
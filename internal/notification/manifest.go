@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/github"
+	"go.yaml.in/yaml/v3"
+)
+
+// maxManifestFilesForAnnotations is the maximum number of files to find the failed resources.
+// Each file requires an API call.
+const maxManifestFilesForAnnotations = 20
+
+// generateCheckRunAnnotations finds the failed resources in the changed files of the pull request.
+// The line numbers are of the head commit of the pull request.
+// This is best-effort, so an error is logged and it returns the annotations found so far.
+func (c client) generateCheckRunAnnotations(ctx context.Context, repository github.Repository, pull github.PullRequest,
+	relatedFiles []string, failedResources []FailedResource) []github.CheckRunAnnotation {
+	if len(failedResources) == 0 {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+	var files []string
+	for _, file := range relatedFiles {
+		if ext := path.Ext(file); ext == ".yaml" || ext == ".yml" {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+	if len(files) > maxManifestFilesForAnnotations {
+		files = files[:maxManifestFilesForAnnotations]
+	}
+
+	var annotations []github.CheckRunAnnotation
+	for _, file := range files {
+		content, err := c.ghc.GetFileContent(ctx, repository, pull.HeadSHA, file)
+		if github.IsNotFoundError(err) {
+			// The file was deleted by the pull request.
+			continue
+		}
+		if err != nil {
+			logger.Error(err, "unable to get the manifest", "file", file, "pullNumber", pull.Number)
+			continue
+		}
+		annotations = append(annotations, generateCheckRunAnnotationsOfManifest(file, content, failedResources)...)
+	}
+	return annotations
+}
+
+func generateCheckRunAnnotationsOfManifest(file, content string, failedResources []FailedResource) []github.CheckRunAnnotation {
+	var annotations []github.CheckRunAnnotation
+	for _, r := range failedResources {
+		line := findResourceInManifest(content, r)
+		if line == 0 {
+			continue
+		}
+		annotations = append(annotations, github.CheckRunAnnotation{
+			Path:      file,
+			StartLine: line,
+			EndLine:   line,
+			Level:     "failure",
+			Title:     fmt.Sprintf("%s %s %s", r.Status, r.Kind, r.Name),
+			Message:   r.Message,
+		})
+	}
+	return annotations
+}
+
+// findResourceInManifest returns the line number of the resource in the YAML manifest, or 0 if not found.
+// If the manifest does not have the namespace, it is considered as the same namespace.
+func findResourceInManifest(content string, r FailedResource) int {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			// io.EOF or invalid YAML
+			return 0
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+		if getYAMLMappingValue(root, "kind").Value != r.Kind {
+			continue
+		}
+		metadata := getYAMLMappingValue(root, "metadata")
+		if getYAMLMappingValue(metadata, "name").Value != r.Name {
+			continue
+		}
+		namespace := getYAMLMappingValue(metadata, "namespace").Value
+		if namespace != "" && r.Namespace != "" && namespace != r.Namespace {
+			continue
+		}
+		return root.Line
+	}
+}
+
+// getYAMLMappingValue returns the value of the key in the mapping node.
+// It returns an empty node if not found.
+func getYAMLMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return &yaml.Node{}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return &yaml.Node{}
+}
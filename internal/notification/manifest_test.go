@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+func Test_generateCheckRunAnnotationsOfManifest(t *testing.T) {
+	const manifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+---
+# comment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bar
+`
+	failedResources := []FailedResource{
+		{Kind: "Deployment", Namespace: "default", Name: "foo", Status: "SyncFailed", Message: "invalid spec"},
+		{Kind: "Deployment", Namespace: "default", Name: "bar", Status: "Degraded", Message: "crash loop"},
+		{Kind: "Deployment", Namespace: "other", Name: "foo", Status: "SyncFailed", Message: "not in the manifest"},
+		{Kind: "ConfigMap", Namespace: "default", Name: "foo", Status: "SyncFailed", Message: "not in the manifest"},
+	}
+	got := generateCheckRunAnnotationsOfManifest("app/deployment.yaml", manifest, failedResources)
+	want := []github.CheckRunAnnotation{
+		{
+			Path:      "app/deployment.yaml",
+			StartLine: 7,
+			EndLine:   7,
+			Level:     "failure",
+			Title:     "SyncFailed Deployment foo",
+			Message:   "invalid spec",
+		},
+		{
+			Path:      "app/deployment.yaml",
+			StartLine: 15,
+			EndLine:   15,
+			Level:     "failure",
+			Title:     "Degraded Deployment bar",
+			Message:   "crash loop",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_findResourceInManifest(t *testing.T) {
+	t.Run("invalid YAML", func(t *testing.T) {
+		got := findResourceInManifest("kind: [", FailedResource{Kind: "Deployment", Name: "foo"})
+		if got != 0 {
+			t.Errorf("findResourceInManifest wants 0 but was %d", got)
+		}
+	})
+	t.Run("not a mapping", func(t *testing.T) {
+		got := findResourceInManifest("- foo\n---\nkind: Deployment\nmetadata:\n  name: foo\n", FailedResource{Kind: "Deployment", Name: "foo"})
+		if got != 3 {
+			t.Errorf("findResourceInManifest wants 3 but was %d", got)
+		}
+	})
+}
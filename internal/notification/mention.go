@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+func isMentionOnFailureEnabled() bool {
+	return os.Getenv("FEATURE_MENTION_ON_FAILURE") == "true"
+}
+
+// loadCodeOwners returns the CODEOWNERS of the revision if enabled.
+// This is optional, so an error is logged and it returns nil.
+func (c client) loadCodeOwners(ctx context.Context, comment Comment) github.CodeOwners {
+	if os.Getenv("FEATURE_MENTION_CODEOWNERS") != "true" {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+	codeOwners, err := c.ghc.GetCodeOwners(ctx, comment.GitHubRepository, comment.SourceRevision.Revision)
+	if err != nil {
+		logger.Error(err, "unable to get the CODEOWNERS")
+		return nil
+	}
+	return codeOwners
+}
+
+// getPullRequestParticipants returns the users who merged or reviewed the pull request.
+// This is optional, so an error is logged and it returns nil.
+func (c client) getPullRequestParticipants(ctx context.Context, repository github.Repository, pullNumber int) *github.PullRequestParticipants {
+	participants, err := c.ghc.GetPullRequestParticipants(ctx, repository, pullNumber)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "unable to get the participants of the pull request", "pullNumber", pullNumber)
+		return nil
+	}
+	return participants
+}
+
+// generateMentions returns the users and teams to mention in the pull request.
+// If the related files have the code owners, they take precedence over the people of the pull request.
+// getParticipants is called only if no code owner is found, because it requires the API calls.
+func generateMentions(pull github.PullRequest, relatedFiles []string, codeOwners github.CodeOwners,
+	getParticipants func() *github.PullRequestParticipants) []string {
+	var mentions []string
+	for _, file := range relatedFiles {
+		for _, owner := range codeOwners.Find(file) {
+			// An owner may be an email address, which cannot be mentioned.
+			if strings.HasPrefix(owner, "@") {
+				mentions = append(mentions, owner)
+			}
+		}
+	}
+	if len(mentions) == 0 {
+		logins := append([]string{pull.Author}, pull.RequestedReviewers...)
+		if participants := getParticipants(); participants != nil {
+			logins = append(logins, participants.MergedBy)
+			logins = append(logins, participants.Reviewers...)
+		}
+		for _, login := range logins {
+			if login == "" || strings.HasSuffix(login, "[bot]") {
+				continue
+			}
+			mentions = append(mentions, "@"+login)
+		}
+	}
+	slices.Sort(mentions)
+	return slices.Compact(mentions)
+}
+
+// mentionPullRequest creates a comment to mention the users.
+// GitHub does not notify a mention added by editing a comment,
+// so it creates a comment once for each transition instead of updating the comment of the application.
+func (c client) mentionPullRequest(ctx context.Context, comment Comment, app argocdv1alpha1.Application, pullNumber int, mentions []string) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	marker := fmt.Sprintf("<!-- argocd-commenter:mention=%s/%s,time=%s -->",
+		app.Namespace, app.Name, comment.Time.UTC().Format(time.RFC3339))
+	return c.upsertPullRequestComment(ctx, comment.GitHubRepository, pullNumber, marker,
+		func(*github.IssueComment) string {
+			return generateMentionCommentBody(marker, comment, mentions)
+		})
+}
+
+func generateMentionCommentBody(marker string, comment Comment, mentions []string) string {
+	return fmt.Sprintf("%s\n%s %s", marker, strings.Join(mentions, " "), summarizeCommentBody(comment.Body))
+}
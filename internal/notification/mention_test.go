@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+func Test_generateMentions(t *testing.T) {
+	pull := github.PullRequest{
+		Number:             101,
+		Author:             "alice",
+		RequestedReviewers: []string{"alice", "renovate[bot]", "owner/reviewers"},
+	}
+	getParticipants := func() *github.PullRequestParticipants {
+		return &github.PullRequestParticipants{MergedBy: "bob", Reviewers: []string{"carol", "alice"}}
+	}
+	getParticipantsMustNotBeCalled := func() *github.PullRequestParticipants {
+		t.Errorf("getParticipants must not be called")
+		return nil
+	}
+
+	t.Run("no code owners", func(t *testing.T) {
+		got := generateMentions(pull, []string{"applications/app1/deployment.yaml"}, nil, getParticipants)
+		want := []string{"@alice", "@bob", "@carol", "@owner/reviewers"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("code owners of the related files", func(t *testing.T) {
+		codeOwners := github.CodeOwners{
+			{Pattern: "*", Owners: []string{"@owner/everyone"}},
+			{Pattern: "/applications/app1/", Owners: []string{"@owner/team1", "team1@example.com"}},
+			{Pattern: "/applications/app2/", Owners: []string{"@owner/team2"}},
+		}
+		got := generateMentions(pull, []string{"applications/app1/deployment.yaml", "applications/app1/service.yaml"}, codeOwners, getParticipantsMustNotBeCalled)
+		want := []string{"@owner/team1"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no code owner of the related files", func(t *testing.T) {
+		codeOwners := github.CodeOwners{
+			{Pattern: "/applications/app2/", Owners: []string{"@owner/team2"}},
+		}
+		got := generateMentions(pull, []string{"applications/app1/deployment.yaml"}, codeOwners, getParticipants)
+		want := []string{"@alice", "@bob", "@carol", "@owner/reviewers"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("participants not available", func(t *testing.T) {
+		got := generateMentions(pull, []string{"applications/app1/deployment.yaml"}, nil,
+			func() *github.PullRequestParticipants { return nil })
+		want := []string{"@alice", "@owner/reviewers"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -21,9 +21,12 @@ var SyncOperationPhasesForComment = []synccommon.OperationPhase{
 
 func (c client) CreateCommentsOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
 	var errs []error
+	templates := c.loadTemplates(ctx, app.Namespace)
 	sourceRevisions := argocd.GetSourceRevisions(app)
 	for _, sourceRevision := range sourceRevisions {
-		comment := generateCommentOnPhaseChanged(app, argocdURL, sourceRevision)
+		comparison := c.compareRevisions(ctx, sourceRevision)
+		sourceRevision = resolveRollback(sourceRevision, comparison)
+		comment := generateCommentOnPhaseChanged(ctx, app, argocdURL, sourceRevision, comparison, templates)
 		if comment == nil {
 			continue
 		}
@@ -34,12 +37,16 @@ func (c client) CreateCommentsOnPhaseChanged(ctx context.Context, app argocdv1al
 	return errors.Join(errs...)
 }
 
-func generateCommentOnPhaseChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision) *Comment {
+func generateCommentOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison, templates *Templates) *Comment {
 	repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
 	if repository == nil {
 		return nil
 	}
-	body := generateCommentBodyOnPhaseChanged(app, argocdURL, sourceRevision)
+	body := generateCommentBodyOnPhaseChanged(ctx, app, argocdURL, sourceRevision, comparison, templates)
+	if body != "" && sourceRevision.Rollback {
+		body = generateCommentBodyOnRollback(ctx, app, argocdURL, sourceRevision, string(argocd.GetSyncOperationPhase(app)),
+			getFailedResourcesOnPhaseChanged(app), isSyncOperationFailed(app), templates)
+	}
 	if body == "" {
 		return nil
 	}
@@ -47,33 +54,84 @@ func generateCommentOnPhaseChanged(app argocdv1alpha1.Application, argocdURL str
 		GitHubRepository: *repository,
 		SourceRevision:   sourceRevision,
 		Body:             body,
+		Time:             argocd.GetLastOperationAt(app).Time,
+		Failed:           isSyncOperationFailed(app),
+		Reaction:         reactionsOnPhase[argocd.GetSyncOperationPhase(app)],
+		Comparison:       comparison,
 	}
 }
 
-func generateCommentBodyOnPhaseChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision) string {
+func isSyncOperationFailed(app argocdv1alpha1.Application) bool {
+	phase := argocd.GetSyncOperationPhase(app)
+	return phase == synccommon.OperationFailed || phase == synccommon.OperationError
+}
+
+var reactionsOnPhase = map[synccommon.OperationPhase]string{
+	synccommon.OperationRunning: github.ReactionEyes,
+	synccommon.OperationFailed:  github.ReactionConfused,
+	synccommon.OperationError:   github.ReactionConfused,
+}
+
+var commentTemplateKeysOnPhase = map[synccommon.OperationPhase]string{
+	synccommon.OperationRunning:   TemplateKeyCommentOnPhaseRunning,
+	synccommon.OperationSucceeded: TemplateKeyCommentOnPhaseSucceeded,
+	synccommon.OperationFailed:    TemplateKeyCommentOnPhaseFailed,
+	synccommon.OperationError:     TemplateKeyCommentOnPhaseError,
+}
+
+func generateCommentBodyOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison, templates *Templates) string {
+	key, ok := commentTemplateKeysOnPhase[argocd.GetSyncOperationPhase(app)]
+	if !ok {
+		return ""
+	}
+	data := newTemplateData(app, argocdURL, sourceRevision, getFailedResourcesOnPhaseChanged(app))
+	data.Comparison = comparison
+	return templates.renderOrDefault(ctx, key, data, func() string {
+		return generateBuiltinCommentBodyOnPhaseChanged(app, argocdURL, sourceRevision, comparison)
+	})
+}
+
+func generateBuiltinCommentBodyOnPhaseChanged(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, comparison *github.Comparison) string {
 	if app.Status.OperationState == nil {
 		return ""
 	}
 	argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, app.Name)
 	phase := app.Status.OperationState.Phase
+	comparisonText := generateComparisonText(comparison)
+	resourceSummary := generateResourceSummary(app.Status.OperationState.SyncResult)
 	switch phase {
 	case synccommon.OperationRunning:
-		return fmt.Sprintf(":warning: Syncing [%s](%s) to %s", app.Name, argocdApplicationURL, sourceRevision.Revision)
+		return appendSection(
+			fmt.Sprintf(":warning: Syncing [%s](%s) to %s", app.Name, argocdApplicationURL, sourceRevision.Revision),
+			comparisonText,
+		)
 	case synccommon.OperationSucceeded:
-		return fmt.Sprintf(":white_check_mark: Synced [%s](%s) to %s", app.Name, argocdApplicationURL, sourceRevision.Revision)
+		return appendSection(appendSection(
+			fmt.Sprintf(":white_check_mark: Synced [%s](%s) to %s", app.Name, argocdApplicationURL, sourceRevision.Revision),
+			comparisonText),
+			resourceSummary,
+		)
 	case synccommon.OperationFailed:
-		return fmt.Sprintf("## :x: Failed to sync [%s](%s) to %s\n%s",
-			app.Name,
-			argocdApplicationURL,
-			sourceRevision.Revision,
-			generateCommentResourcesOnPhaseChanged(app.Status.OperationState.SyncResult),
+		return appendSection(appendSection(
+			fmt.Sprintf("## :x: Failed to sync [%s](%s) to %s\n%s",
+				app.Name,
+				argocdApplicationURL,
+				sourceRevision.Revision,
+				generateCommentResourcesOnPhaseChanged(app.Status.OperationState.SyncResult),
+			),
+			comparisonText),
+			resourceSummary,
 		)
 	case synccommon.OperationError:
-		return fmt.Sprintf("## :x: Sync error [%s](%s) at %s\n%s",
-			app.Name,
-			argocdApplicationURL,
-			sourceRevision.Revision,
-			generateCommentResourcesOnPhaseChanged(app.Status.OperationState.SyncResult),
+		return appendSection(appendSection(
+			fmt.Sprintf("## :x: Sync error [%s](%s) at %s\n%s",
+				app.Name,
+				argocdApplicationURL,
+				sourceRevision.Revision,
+				generateCommentResourcesOnPhaseChanged(app.Status.OperationState.SyncResult),
+			),
+			comparisonText),
+			resourceSummary,
 		)
 	}
 	return ""
@@ -18,55 +18,70 @@ var SyncOperationPhasesForDeploymentStatus = []synccommon.OperationPhase{
 	synccommon.OperationError,
 }
 
-func (c client) CreateDeploymentStatusOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string) error {
-	ds := generateDeploymentStatusOnPhaseChanged(app, argocdURL)
+func (c client) CreateDeploymentStatusOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error) {
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
+	if deployment == nil {
+		return "", nil
+	}
+	templates := c.loadTemplates(ctx, app.Namespace)
+	ds := generateDeploymentStatusOnPhaseChanged(ctx, app, argocdURL, *deployment, templates)
 	if ds == nil {
-		return nil
+		return "", nil
 	}
 	if err := c.createDeploymentStatus(ctx, *ds); err != nil {
-		return fmt.Errorf("unable to create a deployment status: %w", err)
+		return "", fmt.Errorf("unable to create a deployment status: %w", err)
 	}
-	return nil
+	return ds.GitHubDeploymentStatus.State, nil
 }
 
-func generateDeploymentStatusOnPhaseChanged(app argocdv1alpha1.Application, argocdURL string) *DeploymentStatus {
-	deploymentURL := argocd.GetDeploymentURL(app)
-	deployment := github.ParseDeploymentURL(deploymentURL)
-	if deployment == nil {
-		return nil
-	}
+var deploymentStatusStatesOnPhase = map[synccommon.OperationPhase]string{
+	synccommon.OperationRunning:   "queued",
+	synccommon.OperationSucceeded: "in_progress",
+	synccommon.OperationFailed:    "failure",
+	synccommon.OperationError:     "failure",
+}
 
+var deploymentStatusTemplateKeysOnPhase = map[synccommon.OperationPhase]string{
+	synccommon.OperationRunning:   TemplateKeyDeploymentStatusOnPhaseRunning,
+	synccommon.OperationSucceeded: TemplateKeyDeploymentStatusOnPhaseSucceeded,
+	synccommon.OperationFailed:    TemplateKeyDeploymentStatusOnPhaseFailed,
+	synccommon.OperationError:     TemplateKeyDeploymentStatusOnPhaseError,
+}
+
+func generateDeploymentStatusOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, deployment github.Deployment, templates *Templates) *DeploymentStatus {
 	phase := argocd.GetSyncOperationPhase(app)
 	if phase == "" {
 		return nil
 	}
 
 	ds := DeploymentStatus{
-		GitHubDeployment: *deployment,
+		GitHubDeployment: deployment,
 		GitHubDeploymentStatus: github.DeploymentStatus{
 			LogURL:         fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
-			Description:    trimDescription(generateDeploymentStatusDescriptionOnPhaseChanged(app)),
+			Description:    trimDescription(generateDeploymentStatusDescriptionOnPhaseChanged(ctx, app, argocdURL, templates)),
 			EnvironmentURL: argocd.GetApplicationExternalURL(app),
 		},
 	}
-	switch phase {
-	case synccommon.OperationRunning:
-		ds.GitHubDeploymentStatus.State = "queued"
-		return &ds
-	case synccommon.OperationSucceeded:
-		ds.GitHubDeploymentStatus.State = "in_progress"
-		return &ds
-	case synccommon.OperationFailed:
-		ds.GitHubDeploymentStatus.State = "failure"
-		return &ds
-	case synccommon.OperationError:
-		ds.GitHubDeploymentStatus.State = "failure"
-		return &ds
+	state, ok := deploymentStatusStatesOnPhase[phase]
+	if !ok {
+		return nil
+	}
+	ds.GitHubDeploymentStatus.State = state
+	return &ds
+}
+
+func generateDeploymentStatusDescriptionOnPhaseChanged(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, templates *Templates) string {
+	key, ok := deploymentStatusTemplateKeysOnPhase[argocd.GetSyncOperationPhase(app)]
+	if !ok {
+		return ""
 	}
-	return nil
+	data := newTemplateData(app, argocdURL, argocd.SourceRevision{}, getFailedResourcesOnPhaseChanged(app))
+	return templates.renderOrDefault(ctx, key, data, func() string {
+		return generateBuiltinDeploymentStatusDescriptionOnPhaseChanged(app)
+	})
 }
 
-func generateDeploymentStatusDescriptionOnPhaseChanged(app argocdv1alpha1.Application) string {
+func generateBuiltinDeploymentStatusDescriptionOnPhaseChanged(app argocdv1alpha1.Application) string {
 	phase := argocd.GetSyncOperationPhase(app)
 	if phase == "" {
 		return ""
@@ -0,0 +1,108 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// CreateDeploymentStatusOnProgressDeadlineExceeded creates a failure deployment status
+// when the application does not become healthy within the progress deadline.
+func (c client) CreateDeploymentStatusOnProgressDeadlineExceeded(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error) {
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
+	if deployment == nil {
+		return "", nil
+	}
+	templates := c.loadTemplates(ctx, app.Namespace)
+	data := newTemplateData(app, argocdURL, argocd.SourceRevision{}, getUnhealthyResources(app))
+	description := templates.renderOrDefault(ctx, TemplateKeyDeploymentStatusOnProgressDeadlineExceeded, data, func() string {
+		return generateBuiltinDeploymentStatusDescriptionOnProgressDeadlineExceeded(app)
+	})
+	ds := DeploymentStatus{
+		GitHubDeployment: *deployment,
+		GitHubDeploymentStatus: github.DeploymentStatus{
+			State:          "failure",
+			LogURL:         fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
+			Description:    trimDescription(description),
+			EnvironmentURL: argocd.GetApplicationExternalURL(app),
+		},
+	}
+	if err := c.createDeploymentStatus(ctx, ds); err != nil {
+		return "", fmt.Errorf("unable to create a deployment status: %w", err)
+	}
+	return ds.GitHubDeploymentStatus.State, nil
+}
+
+func generateBuiltinDeploymentStatusDescriptionOnProgressDeadlineExceeded(app argocdv1alpha1.Application) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Progress deadline exceeded:\n")
+	for _, r := range getUnhealthyResources(app) {
+		fmt.Fprintf(&b, "%s/%s: %s: %s\n", r.Namespace, r.Name, r.Status, r.Message)
+	}
+	return b.String()
+}
+
+// CreateCommentsOnProgressDeadlineExceeded creates a comment listing the resources not healthy
+// when the application does not become healthy within the progress deadline.
+func (c client) CreateCommentsOnProgressDeadlineExceeded(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, progressDeadline time.Duration) error {
+	var errs []error
+	templates := c.loadTemplates(ctx, app.Namespace)
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+		if repository == nil {
+			continue
+		}
+		data := newTemplateData(app, argocdURL, sourceRevision, getUnhealthyResources(app))
+		body := templates.renderOrDefault(ctx, TemplateKeyCommentOnProgressDeadlineExceeded, data, func() string {
+			return generateBuiltinCommentBodyOnProgressDeadlineExceeded(app, argocdURL, sourceRevision, progressDeadline)
+		})
+		comment := Comment{
+			GitHubRepository: *repository,
+			SourceRevision:   sourceRevision,
+			Body:             body,
+			Time:             time.Now(),
+			Failed:           true,
+			Reaction:         github.ReactionConfused,
+			Comparison:       c.compareRevisions(ctx, sourceRevision),
+		}
+		if err := c.createComment(ctx, comment, app); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func generateBuiltinCommentBodyOnProgressDeadlineExceeded(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, progressDeadline time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## :x: [%s](%s/applications/%s) did not become healthy within %s at %s:\n",
+		app.Name, argocdURL, app.Name, progressDeadline, sourceRevision.Revision)
+	for _, r := range getUnhealthyResources(app) {
+		fmt.Fprintf(&b, "- %s `%s/%s`: %s\n", r.Status, r.Namespace, r.Name, r.Message)
+	}
+	return b.String()
+}
+
+// getUnhealthyResources returns the resources which are not healthy.
+func getUnhealthyResources(app argocdv1alpha1.Application) []FailedResource {
+	var resources []FailedResource
+	for _, r := range app.Status.Resources {
+		if r.Health == nil || r.Health.Status == health.HealthStatusHealthy {
+			continue
+		}
+		resources = append(resources, FailedResource{
+			Kind:      r.Kind,
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Status:    string(r.Health.Status),
+			Message:   r.Health.Message,
+		})
+	}
+	return resources
+}
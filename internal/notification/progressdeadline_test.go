@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateBuiltinCommentBodyOnProgressDeadlineExceeded(t *testing.T) {
+	app := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1"},
+		Status: argocdv1alpha1.ApplicationStatus{
+			Health: argocdv1alpha1.AppHealthStatus{Status: health.HealthStatusProgressing},
+			Resources: []argocdv1alpha1.ResourceStatus{
+				{
+					Namespace: "default",
+					Name:      "server",
+					Health: &argocdv1alpha1.HealthStatus{
+						Status:  health.HealthStatusProgressing,
+						Message: "Waiting for rollout to finish",
+					},
+				},
+				{
+					Namespace: "default",
+					Name:      "worker",
+					Health:    &argocdv1alpha1.HealthStatus{Status: health.HealthStatusHealthy},
+				},
+				{
+					Namespace: "default",
+					Name:      "config",
+				},
+			},
+		},
+	}
+	got := generateBuiltinCommentBodyOnProgressDeadlineExceeded(app, "https://argocd.example.com",
+		argocd.SourceRevision{Revision: "aaaaaaa"}, 10*time.Minute)
+	want := "## :x: [app1](https://argocd.example.com/applications/app1) did not become healthy within 10m0s at aaaaaaa:\n" +
+		"- Progressing `default/server`: Waiting for rollout to finish\n"
+	if got != want {
+		t.Errorf("body wants\n%s\nbut got\n%s", want, got)
+	}
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// getProjectField returns the name of the project field and option of the application.
+// If the annotation is not set, the field name falls back to the environment variable PROJECT_FIELD_NAME.
+func getProjectField(app argocdv1alpha1.Application) (fieldName, optionName string) {
+	fieldName, optionName = argocd.GetProjectField(app)
+	if fieldName == "" {
+		fieldName = os.Getenv("PROJECT_FIELD_NAME")
+	}
+	return fieldName, optionName
+}
+
+// UpdateProjectFields sets the option of the project field to the project items
+// linked to the related pull requests and their closing issues.
+// It does nothing unless the application is healthy and the field is configured.
+func (c client) UpdateProjectFields(ctx context.Context, app argocdv1alpha1.Application, loadRelatedPulls RelatedPullRequestsLoader) error {
+	if app.Status.Health.Status != health.HealthStatusHealthy {
+		return nil
+	}
+	fieldName, optionName := getProjectField(app)
+	if fieldName == "" || optionName == "" {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx).WithValues("field", fieldName, "option", optionName)
+
+	relatedPullsOfSources, err := loadRelatedPulls()
+	errs := []error{err}
+	for _, relatedPulls := range relatedPullsOfSources {
+		issues, err := c.listClosingIssues(ctx, relatedPulls.Repository, relatedPulls.PullRequests)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		targets := make([]github.Issue, 0, len(relatedPulls.PullRequests)+len(issues))
+		for _, pull := range relatedPulls.PullRequests {
+			targets = append(targets, github.Issue{Repository: relatedPulls.Repository, Number: pull.Number})
+		}
+		for _, issue := range issues {
+			targets = append(targets, issue.Issue)
+		}
+		for _, target := range targets {
+			updated, err := c.ghc.SetProjectItemsFieldValue(ctx, target.Repository, target.Number, fieldName, optionName)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if updated > 0 {
+				logger.Info("Updated the project items", "target", target, "count", updated)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -23,19 +23,27 @@ func filterPullRequestsRelatedToEvent(pulls []github.PullRequest, sourceRevision
 }
 
 func isPullRequestRelatedToEvent(pull github.PullRequest, sourceRevision argocd.SourceRevision, manifestGeneratePaths []string) bool {
+	return len(getFilesRelatedToEvent(pull, sourceRevision, manifestGeneratePaths)) > 0
+}
+
+// getFilesRelatedToEvent returns the files of the pull request under the source path or manifest generate paths.
+func getFilesRelatedToEvent(pull github.PullRequest, sourceRevision argocd.SourceRevision, manifestGeneratePaths []string) []string {
 	absSourcePath := path.Join("/", sourceRevision.Source.Path)
+	var files []string
 	for _, file := range pull.Files {
 		absPullFile := path.Join("/", file)
 		if strings.HasPrefix(absPullFile, absSourcePath) {
-			return true
+			files = append(files, file)
+			continue
 		}
 		for _, manifestGeneratePath := range manifestGeneratePaths {
 			if strings.HasPrefix(absPullFile, manifestGeneratePath) {
-				return true
+				files = append(files, file)
+				break
 			}
 		}
 	}
-	return false
+	return files
 }
 
 // getManifestGeneratePaths returns canonical paths of "argocd.argoproj.io/manifest-generate-paths" annotation.
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+const (
+	// NotificationModeComment notifies a transition by a comment. This is the default.
+	NotificationModeComment = "comment"
+	// NotificationModeReaction notifies a transition by a reaction to the pull request, without any comment.
+	NotificationModeReaction = "reaction"
+)
+
+// getNotificationMode returns the mode in the annotation of the application,
+// or the environment variable NOTIFICATION_MODE if not set.
+func getNotificationMode(app argocdv1alpha1.Application) string {
+	if mode := argocd.GetNotificationMode(app); mode != "" {
+		return mode
+	}
+	if mode := os.Getenv("NOTIFICATION_MODE"); mode != "" {
+		return mode
+	}
+	return NotificationModeComment
+}
+
+// transitionReactions are the reactions which represent a transition of the application.
+// A pull request should have only the latest one of them.
+var transitionReactions = []string{github.ReactionEyes, github.ReactionRocket, github.ReactionConfused}
+
+func (c client) createReactions(ctx context.Context, comment Comment, pulls []github.PullRequest) error {
+	if comment.Reaction == "" {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx).WithValues("reaction", comment.Reaction)
+	var errs []error
+	for _, pull := range pulls {
+		if err := c.replaceReaction(ctx, comment.GitHubRepository, pull.Number, comment.Reaction); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Created a reaction to the pull request", "pullNumber", pull.Number)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("unable to create reaction(s) on revision %s: %w", comment.SourceRevision.Revision, err)
+	}
+	return nil
+}
+
+// replaceReaction adds the reaction to the pull request,
+// and then deletes the previous transition reactions added by the same user.
+// The reactions are shared by all Applications related to the pull request,
+// because all of them are added by the same user.
+func (c client) replaceReaction(ctx context.Context, repository github.Repository, pullNumber int, content string) error {
+	created, err := c.ghc.CreatePullRequestReaction(ctx, repository, pullNumber, content)
+	if err != nil {
+		return err
+	}
+	reactions, err := c.ghc.ListPullRequestReactions(ctx, repository, pullNumber)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, reaction := range findOutdatedReactions(reactions, *created) {
+		if err := c.ghc.DeletePullRequestReaction(ctx, repository, pullNumber, reaction.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findOutdatedReactions returns the transition reactions added by the same user as the created one,
+// excluding the created one.
+// It never returns a failure reaction, because it may be added by another Application
+// and removing it would hide the failure.
+func findOutdatedReactions(reactions []github.Reaction, created github.Reaction) []github.Reaction {
+	var outdated []github.Reaction
+	for _, reaction := range reactions {
+		if reaction.User != created.User || reaction.ID == created.ID || reaction.Content == created.Content {
+			continue
+		}
+		if reaction.Content == github.ReactionConfused {
+			continue
+		}
+		if !slices.Contains(transitionReactions, reaction.Content) {
+			continue
+		}
+		outdated = append(outdated, reaction)
+	}
+	return outdated
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	gogithub "github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getNotificationMode(t *testing.T) {
+	appWithAnnotation := argocdv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"argocd-commenter.int128.github.io/notification-mode": "comment",
+			},
+		},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("NOTIFICATION_MODE", "")
+		if got := getNotificationMode(argocdv1alpha1.Application{}); got != NotificationModeComment {
+			t.Errorf("getNotificationMode wants %s but was %s", NotificationModeComment, got)
+		}
+	})
+	t.Run("environment variable", func(t *testing.T) {
+		t.Setenv("NOTIFICATION_MODE", "reaction")
+		if got := getNotificationMode(argocdv1alpha1.Application{}); got != NotificationModeReaction {
+			t.Errorf("getNotificationMode wants %s but was %s", NotificationModeReaction, got)
+		}
+	})
+	t.Run("annotation takes precedence", func(t *testing.T) {
+		t.Setenv("NOTIFICATION_MODE", "reaction")
+		if got := getNotificationMode(appWithAnnotation); got != NotificationModeComment {
+			t.Errorf("getNotificationMode wants %s but was %s", NotificationModeComment, got)
+		}
+	})
+}
+
+func Test_findOutdatedReactions(t *testing.T) {
+	created := github.Reaction{ID: 3, Content: github.ReactionRocket, User: "argocd-commenter[bot]"}
+	reactions := []github.Reaction{
+		{ID: 1, Content: github.ReactionEyes, User: "argocd-commenter[bot]"},
+		{ID: 2, Content: github.ReactionConfused, User: "argocd-commenter[bot]"},
+		created,
+		{ID: 4, Content: github.ReactionEyes, User: "alice"},
+		{ID: 5, Content: "+1", User: "argocd-commenter[bot]"},
+	}
+	got := findOutdatedReactions(reactions, created)
+	want := []github.Reaction{
+		{ID: 1, Content: github.ReactionEyes, User: "argocd-commenter[bot]"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreateReactions_twoApplications(t *testing.T) {
+	repository := github.Repository{Owner: "owner", Name: "repo"}
+	pulls := []github.PullRequest{{Number: 1}}
+	bot := &gogithub.User{Login: gogithub.Ptr("argocd-commenter[bot]")}
+
+	// The reactions to the pull request, shared by the Applications.
+	var reactions []*gogithub.Reaction
+	var sv githubmock.Server
+	sv.Handle("POST /api/v3/repos/owner/repo/issues/1/reactions", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Content string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("could not decode the request: %s", err)
+		}
+		created := &gogithub.Reaction{ID: gogithub.Ptr(int64(len(reactions) + 1)), Content: gogithub.Ptr(req.Content), User: bot}
+		reactions = append(reactions, created)
+		respondJSON(t, created)(w, r)
+	}))
+	sv.Handle("GET /api/v3/repos/owner/repo/issues/1/reactions?per_page=100", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(t, reactions)(w, r)
+	}))
+	for id := range 3 {
+		sv.Handle(fmt.Sprintf("DELETE /api/v3/repos/owner/repo/issues/1/reactions/%d", id+1), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			reactions = slices.DeleteFunc(reactions, func(reaction *gogithub.Reaction) bool { return reaction.GetID() == int64(id+1) })
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+	c := newMockClient(t, &sv)
+	react := func(content string) {
+		t.Helper()
+		comment := Comment{GitHubRepository: repository, Reaction: content}
+		if err := c.createReactions(context.TODO(), comment, pulls); err != nil {
+			t.Fatalf("createReactions error: %s", err)
+		}
+	}
+
+	// app1 is running
+	react(github.ReactionEyes)
+	// app2 is failed
+	react(github.ReactionConfused)
+	// app1 is healthy
+	react(github.ReactionRocket)
+
+	var got []string
+	for _, reaction := range reactions {
+		got = append(got, reaction.GetContent())
+	}
+	want := []string{github.ReactionConfused, github.ReactionRocket}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
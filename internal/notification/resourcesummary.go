@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+)
+
+// The resource summary should leave enough room for the rest of the comment.
+// The whole comment body is truncated to maxCommentBodyLength as well.
+const maxResourceSummaryLength = 50000
+
+// resourceActionsForSummary are always shown in the summary, and others are shown if present.
+var resourceActionsForSummary = []string{"created", "configured", "unchanged", "pruned"}
+
+var otherResourceActions = []string{"failed", "prune skipped", "synced"}
+
+// getResourceAction returns the action to the resource in the sync operation.
+// The message of a synced resource is the output of kubectl apply, such as "deployment.apps/foo configured".
+func getResourceAction(r argocdv1alpha1.ResourceResult) string {
+	switch r.Status {
+	case synccommon.ResultCodePruned:
+		return "pruned"
+	case synccommon.ResultCodeSyncFailed:
+		return "failed"
+	case synccommon.ResultCodePruneSkipped:
+		return "prune skipped"
+	}
+	for _, action := range []string{"created", "configured", "unchanged"} {
+		if r.Message == action || strings.HasSuffix(r.Message, " "+action) {
+			return action
+		}
+	}
+	return "synced"
+}
+
+// generateResourceSummary returns a collapsible section of the resources in the sync result.
+// It returns an empty string if there is no resource.
+func generateResourceSummary(syncResult *argocdv1alpha1.SyncOperationResult) string {
+	if syncResult == nil || len(syncResult.Resources) == 0 {
+		return ""
+	}
+	resources := slices.Clone(syncResult.Resources)
+	slices.SortStableFunc(resources, func(a, b *argocdv1alpha1.ResourceResult) int {
+		return strings.Compare(a.Kind, b.Kind)
+	})
+
+	counts := make(map[string]int)
+	for _, r := range resources {
+		counts[getResourceAction(*r)]++
+	}
+	var countTexts []string
+	for _, action := range resourceActionsForSummary {
+		countTexts = append(countTexts, fmt.Sprintf("%d %s", counts[action], action))
+	}
+	for _, action := range otherResourceActions {
+		if counts[action] > 0 {
+			countTexts = append(countTexts, fmt.Sprintf("%d %s", counts[action], action))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "<details>")
+	fmt.Fprintf(&b, "<summary>Resources: %s</summary>\n", strings.Join(countTexts, ", "))
+	fmt.Fprintln(&b)
+	var lastKind string
+	for i, r := range resources {
+		var line strings.Builder
+		if r.Kind != lastKind {
+			fmt.Fprintf(&line, "#### %s\n", r.Kind)
+			lastKind = r.Kind
+		}
+		namespacedName := r.Namespace + "/" + r.Name
+		fmt.Fprintf(&line, "- `%s`: %s\n", namespacedName, getResourceAction(*r))
+		if b.Len()+line.Len() > maxResourceSummaryLength {
+			fmt.Fprintf(&b, "\n... and %d more resource(s)\n", len(resources)-i)
+			break
+		}
+		b.WriteString(line.String())
+	}
+	fmt.Fprintln(&b, "</details>")
+	return b.String()
+}
+
+// appendSection appends the section to the body with a blank line.
+// It returns the body as-is if the section is empty.
+func appendSection(body, section string) string {
+	if section == "" {
+		return body
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + section
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_generateResourceSummary(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		if got := generateResourceSummary(nil); got != "" {
+			t.Errorf("summary wants empty but was %q", got)
+		}
+	})
+
+	t.Run("grouped by kind", func(t *testing.T) {
+		syncResult := &argocdv1alpha1.SyncOperationResult{
+			Resources: argocdv1alpha1.ResourceResults{
+				{Kind: "Service", Namespace: "default", Name: "echoserver", Status: synccommon.ResultCodeSynced, Message: "service/echoserver unchanged"},
+				{Kind: "Deployment", Namespace: "default", Name: "echoserver", Status: synccommon.ResultCodeSynced, Message: "deployment.apps/echoserver configured"},
+				{Kind: "ConfigMap", Namespace: "default", Name: "config-v2", Status: synccommon.ResultCodeSynced, Message: "configmap/config-v2 created"},
+				{Kind: "ConfigMap", Namespace: "default", Name: "config-v1", Status: synccommon.ResultCodePruned, Message: "pruned"},
+			},
+		}
+		got := generateResourceSummary(syncResult)
+		const want = "<details>\n" +
+			"<summary>Resources: 1 created, 1 configured, 1 unchanged, 1 pruned</summary>\n" +
+			"\n" +
+			"#### ConfigMap\n" +
+			"- `default/config-v2`: created\n" +
+			"- `default/config-v1`: pruned\n" +
+			"#### Deployment\n" +
+			"- `default/echoserver`: configured\n" +
+			"#### Service\n" +
+			"- `default/echoserver`: unchanged\n" +
+			"</details>\n"
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("summary mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var resources argocdv1alpha1.ResourceResults
+		for i := range 10000 {
+			resources = append(resources, &argocdv1alpha1.ResourceResult{
+				Kind:      "ConfigMap",
+				Namespace: "default",
+				Name:      fmt.Sprintf("config-%d", i),
+				Status:    synccommon.ResultCodeSynced,
+				Message:   "unchanged",
+			})
+		}
+		got := generateResourceSummary(&argocdv1alpha1.SyncOperationResult{Resources: resources})
+		if len(got) > maxResourceSummaryLength+100 {
+			t.Errorf("summary length wants <= %d but was %d", maxResourceSummaryLength, len(got))
+		}
+		if !strings.Contains(got, "more resource(s)") {
+			t.Errorf("summary should be truncated")
+		}
+		if !strings.HasSuffix(got, "</details>\n") {
+			t.Errorf("summary should be closed")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// IsRollback returns true if the sync operation deploys an older revision of the first source.
+// If the revision has been deployed before, it is a rollback only if the revision is an ancestor of the previous revision.
+func (c client) IsRollback(ctx context.Context, app argocdv1alpha1.Application) (bool, error) {
+	sourceRevisions := argocd.GetSourceRevisions(app)
+	if len(sourceRevisions) == 0 {
+		return false, nil
+	}
+	sourceRevision := sourceRevisions[0]
+	if sourceRevision.Rollback {
+		return true, nil
+	}
+	if !sourceRevision.Redeployed {
+		return false, nil
+	}
+	repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+	if repository == nil {
+		return false, nil
+	}
+	status, err := c.ghc.GetComparisonStatus(ctx, *repository, sourceRevision.PreviousRevision, sourceRevision.Revision)
+	if err != nil {
+		return false, fmt.Errorf("unable to compare the revisions: %w", err)
+	}
+	return status == github.ComparisonStatusBehind, nil
+}
+
+// resolveRollback sets Rollback if the revision has been deployed before,
+// and it is an ancestor of the previous revision.
+func resolveRollback(sourceRevision argocd.SourceRevision, comparison *github.Comparison) argocd.SourceRevision {
+	if sourceRevision.Redeployed && comparison != nil && comparison.Status == github.ComparisonStatusBehind {
+		sourceRevision.Rollback = true
+	}
+	return sourceRevision
+}
+
+// generateCommentBodyOnRollback returns a comment body to the pull requests removed by the rollback.
+// The status is the sync operation phase or health status.
+func generateCommentBodyOnRollback(ctx context.Context, app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, status string, failedResources []FailedResource, failed bool, templates *Templates) string {
+	data := newTemplateData(app, argocdURL, sourceRevision, failedResources)
+	return templates.renderOrDefault(ctx, TemplateKeyCommentOnRollback, data, func() string {
+		return generateBuiltinCommentBodyOnRollback(app, argocdURL, sourceRevision, status, failedResources, failed)
+	})
+}
+
+func generateBuiltinCommentBodyOnRollback(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, status string, failedResources []FailedResource, failed bool) string {
+	argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, app.Name)
+	target := fmt.Sprintf("[%s](%s) from %s to %s", app.Name, argocdApplicationURL, sourceRevision.PreviousRevision, sourceRevision.Revision)
+	if failed {
+		var b strings.Builder
+		fmt.Fprintf(&b, "## :x: Failed to roll back %s: %s\n", target, status)
+		for _, r := range failedResources {
+			fmt.Fprintf(&b, "- %s `%s/%s`: %s\n", r.Status, r.Namespace, r.Name, r.Message)
+		}
+		return b.String()
+	}
+	if status == string(synccommon.OperationRunning) {
+		return fmt.Sprintf(":rewind: Rolling back %s\nThe changes of this pull request will be removed from the deployment.", target)
+	}
+	return fmt.Sprintf(":rewind: Rolled back %s: %s\nThe changes of this pull request have been removed from the deployment.", target, status)
+}
+
+// listRolledBackPullRequests returns the pull requests between the revision and the previous revision,
+// that is, the pull requests whose changes are removed by the rollback.
+func (c client) listRolledBackPullRequests(ctx context.Context, repository github.Repository, sourceRevision argocd.SourceRevision) ([]github.PullRequest, error) {
+	comparison, err := c.ghc.CompareRevisions(ctx, repository, sourceRevision.Revision, sourceRevision.PreviousRevision)
+	if err != nil {
+		return nil, err
+	}
+	return c.ghc.ListPullRequestsInComparison(ctx, repository, *comparison)
+}
+
+// CreateDeploymentStatusOnRollback creates a deployment status to the deployment of the revision rolled back from.
+// It sets inactive if the deployment has been succeeded, or failure if it has not been completed.
+// It does nothing if the deployment has already been inactive or failed.
+func (c client) CreateDeploymentStatusOnRollback(ctx context.Context, app argocdv1alpha1.Application, argocdURL, deploymentURL string) (string, error) {
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
+	if deployment == nil {
+		return "", nil
+	}
+	sourceRevisions := argocd.GetSourceRevisions(app)
+	if len(sourceRevisions) == 0 {
+		return "", nil
+	}
+	latestDeploymentStatus, err := c.ghc.FindLatestDeploymentStatus(ctx, *deployment)
+	if err != nil {
+		return "", fmt.Errorf("unable to find the latest deployment status: %w", err)
+	}
+	state := getDeploymentStatusStateOnRollback(latestDeploymentStatus)
+	if state == "" {
+		return "", nil
+	}
+	ds := DeploymentStatus{
+		GitHubDeployment: *deployment,
+		GitHubDeploymentStatus: github.DeploymentStatus{
+			State:  state,
+			LogURL: fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
+			Description: trimDescription(fmt.Sprintf("Rolled back from %s to %s",
+				sourceRevisions[0].PreviousRevision, sourceRevisions[0].Revision)),
+		},
+	}
+	if err := c.createDeploymentStatus(ctx, ds); err != nil {
+		return "", fmt.Errorf("unable to create a deployment status: %w", err)
+	}
+	return state, nil
+}
+
+func getDeploymentStatusStateOnRollback(latestDeploymentStatus *github.DeploymentStatus) string {
+	if latestDeploymentStatus == nil {
+		return "failure"
+	}
+	switch latestDeploymentStatus.State {
+	case "success":
+		return "inactive"
+	case "inactive", "failure", "error":
+		return ""
+	}
+	return "failure"
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateBuiltinCommentBodyOnRollback(t *testing.T) {
+	app := argocdv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app1"}}
+	sourceRevision := argocd.SourceRevision{Revision: "aaaaaaa", PreviousRevision: "bbbbbbb", Rollback: true}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		got := generateBuiltinCommentBodyOnRollback(app, "https://argocd.example.com", sourceRevision, "Succeeded", nil, false)
+		want := ":rewind: Rolled back [app1](https://argocd.example.com/applications/app1) from bbbbbbb to aaaaaaa: Succeeded\n" +
+			"The changes of this pull request have been removed from the deployment."
+		if got != want {
+			t.Errorf("body wants\n%s\nbut got\n%s", want, got)
+		}
+	})
+	t.Run("Failed", func(t *testing.T) {
+		failedResources := []FailedResource{
+			{Namespace: "default", Name: "server", Status: "SyncFailed", Message: "invalid manifest"},
+		}
+		got := generateBuiltinCommentBodyOnRollback(app, "https://argocd.example.com", sourceRevision, "Failed", failedResources, true)
+		want := "## :x: Failed to roll back [app1](https://argocd.example.com/applications/app1) from bbbbbbb to aaaaaaa: Failed\n" +
+			"- SyncFailed `default/server`: invalid manifest\n"
+		if got != want {
+			t.Errorf("body wants\n%s\nbut got\n%s", want, got)
+		}
+	})
+}
+
+func Test_getDeploymentStatusStateOnRollback(t *testing.T) {
+	for _, tc := range []struct {
+		latest *github.DeploymentStatus
+		want   string
+	}{
+		{latest: nil, want: "failure"},
+		{latest: &github.DeploymentStatus{State: "in_progress"}, want: "failure"},
+		{latest: &github.DeploymentStatus{State: "success"}, want: "inactive"},
+		{latest: &github.DeploymentStatus{State: "inactive"}, want: ""},
+		{latest: &github.DeploymentStatus{State: "failure"}, want: ""},
+	} {
+		got := getDeploymentStatusStateOnRollback(tc.latest)
+		if got != tc.want {
+			t.Errorf("getDeploymentStatusStateOnRollback(%+v) wants %q but got %q", tc.latest, tc.want, got)
+		}
+	}
+}
+
+func TestIsRollback(t *testing.T) {
+	startedAt := func(day int) *metav1.Time {
+		mt := metav1.NewTime(time.Date(2021, 1, day, 0, 0, 0, 0, time.UTC))
+		return &mt
+	}
+	// The history is a, b, a and then b is synced.
+	newApplication := func() argocdv1alpha1.Application {
+		return argocdv1alpha1.Application{
+			Spec: argocdv1alpha1.ApplicationSpec{
+				Source: &argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/owner/repo"},
+			},
+			Status: argocdv1alpha1.ApplicationStatus{
+				OperationState: &argocdv1alpha1.OperationState{
+					StartedAt: *startedAt(4),
+					Operation: argocdv1alpha1.Operation{
+						Sync: &argocdv1alpha1.SyncOperation{Revision: "bbbbbbb"},
+					},
+				},
+				History: argocdv1alpha1.RevisionHistories{
+					{Revision: "aaaaaaa", DeployStartedAt: startedAt(1)},
+					{Revision: "bbbbbbb", DeployStartedAt: startedAt(2)},
+					{Revision: "aaaaaaa", DeployStartedAt: startedAt(3)},
+				},
+			},
+		}
+	}
+
+	t.Run("Sync to a descendant revision", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/aaaaaaa...bbbbbbb?per_page=1",
+			respondJSON(t, map[string]any{"status": github.ComparisonStatusAhead}))
+		c := newMockClient(t, &sv)
+		got, err := c.IsRollback(context.TODO(), newApplication())
+		if err != nil {
+			t.Fatalf("IsRollback error: %s", err)
+		}
+		if got {
+			t.Errorf("IsRollback wants false but got true")
+		}
+	})
+	t.Run("Sync to an ancestor revision", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/compare/aaaaaaa...bbbbbbb?per_page=1",
+			respondJSON(t, map[string]any{"status": github.ComparisonStatusBehind}))
+		c := newMockClient(t, &sv)
+		got, err := c.IsRollback(context.TODO(), newApplication())
+		if err != nil {
+			t.Fatalf("IsRollback error: %s", err)
+		}
+		if !got {
+			t.Errorf("IsRollback wants true but got false")
+		}
+	})
+	t.Run("Rollback operation", func(t *testing.T) {
+		var sv githubmock.Server
+		c := newMockClient(t, &sv)
+		app := newApplication()
+		app.Status.OperationState.Operation.Sync.Source = &argocdv1alpha1.ApplicationSource{RepoURL: "https://github.com/owner/repo"}
+		got, err := c.IsRollback(context.TODO(), app)
+		if err != nil {
+			t.Fatalf("IsRollback error: %s", err)
+		}
+		if !got {
+			t.Errorf("IsRollback wants true but got false")
+		}
+	})
+}
+
+func Test_resolveRollback(t *testing.T) {
+	sourceRevision := argocd.SourceRevision{Revision: "aaaaaaa", PreviousRevision: "bbbbbbb", Redeployed: true}
+	for _, tc := range []struct {
+		name       string
+		comparison *github.Comparison
+		want       bool
+	}{
+		{name: "behind", comparison: &github.Comparison{Status: github.ComparisonStatusBehind}, want: true},
+		{name: "ahead", comparison: &github.Comparison{Status: github.ComparisonStatusAhead}, want: false},
+		{name: "diverged", comparison: &github.Comparison{Status: github.ComparisonStatusDiverged}, want: false},
+		{name: "no comparison", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resolveRollback(sourceRevision, tc.comparison)
+			if got.Rollback != tc.want {
+				t.Errorf("Rollback wants %v but got %v", tc.want, got.Rollback)
+			}
+		})
+	}
+}
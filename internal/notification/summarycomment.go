@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+const summaryCommentMarker = "<!-- argocd-commenter:summary -->"
+
+// UpdateSummaryComments creates or updates the summary comment on each pull request related to the application.
+// The summary is rebuilt from the current states of all applications related to the pull request.
+// It does nothing in the reaction mode.
+func (c client) UpdateSummaryComments(ctx context.Context, app argocdv1alpha1.Application, apps []argocdv1alpha1.Application, argocdURL string) error {
+	if getNotificationMode(app) == NotificationModeReaction {
+		return nil
+	}
+	var errs []error
+	for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+		repository := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+		if repository == nil {
+			continue
+		}
+		pulls, err := c.listPullRequests(ctx, *repository, sourceRevision)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to list pull requests of revision %s: %w", sourceRevision.Revision, err))
+			continue
+		}
+		for _, pull := range filterPullRequestsRelatedToEvent(pulls, sourceRevision, app) {
+			rows := generateSummaryRows(*repository, pull, apps)
+			body := generateSummaryCommentBody(rows, argocdURL)
+			if err := c.upsertPullRequestComment(ctx, *repository, pull.Number, summaryCommentMarker,
+				func(*github.IssueComment) string { return body }); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("unable to update summary comment(s): %w", err)
+	}
+	return nil
+}
+
+type summaryRow struct {
+	App            argocdv1alpha1.Application
+	SourceRevision argocd.SourceRevision
+}
+
+// generateSummaryRows returns the applications related to the pull request, sorted by namespace and name.
+func generateSummaryRows(repository github.Repository, pull github.PullRequest, apps []argocdv1alpha1.Application) []summaryRow {
+	var rows []summaryRow
+	for _, app := range apps {
+		manifestGeneratePaths := getManifestGeneratePaths(app)
+		for _, sourceRevision := range argocd.GetSourceRevisions(app) {
+			r := github.ParseRepositoryURL(sourceRevision.Source.RepoURL)
+			if r == nil || *r != repository {
+				continue
+			}
+			if isPullRequestRelatedToEvent(pull, sourceRevision, manifestGeneratePaths) {
+				rows = append(rows, summaryRow{App: app, SourceRevision: sourceRevision})
+				break
+			}
+		}
+	}
+	slices.SortFunc(rows, func(a, b summaryRow) int {
+		return cmp.Or(
+			strings.Compare(a.App.Namespace, b.App.Namespace),
+			strings.Compare(a.App.Name, b.App.Name),
+		)
+	})
+	return rows
+}
+
+func generateSummaryCommentBody(rows []summaryRow, argocdURL string) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, summaryCommentMarker)
+	fmt.Fprintln(&b, "## Argo CD Applications")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "| Application | Sync | Health | Revision |")
+	fmt.Fprintln(&b, "|-------------|------|--------|----------|")
+	for _, row := range rows {
+		argocdApplicationURL := fmt.Sprintf("%s/applications/%s", argocdURL, row.App.Name)
+		fmt.Fprintf(&b, "| [%s](%s) | %s | %s | %s |\n",
+			row.App.Name,
+			argocdApplicationURL,
+			cmp.Or(string(argocd.GetSyncOperationPhase(row.App)), "-"),
+			cmp.Or(string(row.App.Status.Health.Status), "-"),
+			row.SourceRevision.Revision,
+		)
+	}
+	return b.String()
+}
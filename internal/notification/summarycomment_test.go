@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSummaryFixtureApplication(name, repoURL, sourcePath, revision string) argocdv1alpha1.Application {
+	return argocdv1alpha1.Application{
+		ObjectMeta: v1meta.ObjectMeta{Namespace: "argocd", Name: name},
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Source: &argocdv1alpha1.ApplicationSource{RepoURL: repoURL, Path: sourcePath},
+		},
+		Status: argocdv1alpha1.ApplicationStatus{
+			OperationState: &argocdv1alpha1.OperationState{
+				Phase: synccommon.OperationSucceeded,
+				Operation: argocdv1alpha1.Operation{
+					Sync: &argocdv1alpha1.SyncOperation{Revision: revision},
+				},
+			},
+			Health: argocdv1alpha1.AppHealthStatus{Status: health.HealthStatusHealthy},
+		},
+	}
+}
+
+func Test_generateSummaryRows(t *testing.T) {
+	apps := []argocdv1alpha1.Application{
+		newSummaryFixtureApplication("prod", "https://github.com/owner/repo.git", "apps/prod", "bbb"),
+		newSummaryFixtureApplication("dev", "https://github.com/owner/repo.git", "apps/dev", "aaa"),
+		newSummaryFixtureApplication("other", "https://github.com/owner/repo.git", "apps/other", "aaa"),
+		newSummaryFixtureApplication("another-repo", "https://github.com/owner/another.git", "apps/dev", "aaa"),
+	}
+	pull := github.PullRequest{Number: 1, Files: []string{"apps/dev/deployment.yaml", "apps/prod/deployment.yaml"}}
+	rows := generateSummaryRows(github.Repository{Owner: "owner", Name: "repo"}, pull, apps)
+
+	var got []string
+	for _, row := range rows {
+		got = append(got, row.App.Name+"@"+row.SourceRevision.Revision)
+	}
+	want := []string{"dev@aaa", "prod@bbb"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_generateSummaryCommentBody(t *testing.T) {
+	app := newSummaryFixtureApplication("dev", "https://github.com/owner/repo.git", "apps/dev", "aaa")
+	rows := []summaryRow{{App: app, SourceRevision: argocd.GetSourceRevisions(app)[0]}}
+	got := generateSummaryCommentBody(rows, "https://argocd.example.com")
+	const want = summaryCommentMarker + `
+## Argo CD Applications
+
+| Application | Sync | Health | Revision |
+|-------------|------|--------|----------|
+| [dev](https://argocd.example.com/applications/dev) | Succeeded | Healthy | aaa |
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// maxSupersededDeployments is the maximum number of the previous deployments to look up at once.
+// This avoids too many API calls when the environment has a long history.
+const maxSupersededDeployments = 10
+
+// SupersededDeployment represents a deployment which is set to inactive by a newer deployment.
+type SupersededDeployment struct {
+	URL string
+	// Revision is the sha of the deployment.
+	Revision string
+}
+
+// DeactivateSupersededDeployments creates an inactive status to the previous deployments
+// of the same environment, which are still active.
+// It deactivates only the deployments in ownedDeploymentURLs,
+// because the other Applications may deploy to the same environment, such as a monorepo.
+// It returns the deactivated deployments, even if an error occurred on the way.
+func (c client) DeactivateSupersededDeployments(ctx context.Context, app argocdv1alpha1.Application, deploymentURL string, ownedDeploymentURLs []string) ([]SupersededDeployment, error) {
+	if !argocd.ShouldDeactivateSupersededDeployments(app) {
+		return nil, nil
+	}
+	deployment := c.ghc.ParseDeploymentURL(deploymentURL)
+	if deployment == nil {
+		return nil, nil
+	}
+	previousDeployments, err := c.ghc.ListPreviousDeployments(ctx, *deployment, maxSupersededDeployments)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the previous deployments: %w", err)
+	}
+	var superseded []SupersededDeployment
+	var errs []error
+	for _, previousDeployment := range previousDeployments {
+		if !slices.Contains(ownedDeploymentURLs, previousDeployment.URL) {
+			continue
+		}
+		latestDeploymentStatus, err := c.ghc.FindLatestDeploymentStatus(ctx, previousDeployment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to find the latest deployment status: %w", err))
+			continue
+		}
+		if latestDeploymentStatus == nil || latestDeploymentStatus.State != "success" {
+			continue
+		}
+		ds := DeploymentStatus{
+			GitHubDeployment: previousDeployment,
+			GitHubDeploymentStatus: github.DeploymentStatus{
+				State:       "inactive",
+				Description: fmt.Sprintf("Superseded by the deployment %d", deployment.Id),
+			},
+		}
+		if err := c.createDeploymentStatus(ctx, ds); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		superseded = append(superseded, SupersededDeployment{URL: previousDeployment.URL, Revision: previousDeployment.SHA})
+	}
+	return superseded, errors.Join(errs...)
+}
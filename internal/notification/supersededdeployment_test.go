@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	gogithub "github.com/google/go-github/v80/github"
+	"github.com/int128/argocd-commenter/internal/githubmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeactivateSupersededDeployments(t *testing.T) {
+	const deploymentURL = "https://api.github.com/repos/owner/repo/deployments/5"
+
+	t.Run("only success deployments owned by the application", func(t *testing.T) {
+		var sv githubmock.Server
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments/5", respondJSON(t, gogithub.Deployment{
+			ID:          gogithub.Ptr(int64(5)),
+			Environment: gogithub.Ptr("staging"),
+		}))
+		var deployments []*gogithub.Deployment
+		for id := int64(4); id >= 1; id-- {
+			deployments = append(deployments, &gogithub.Deployment{
+				ID:  gogithub.Ptr(id),
+				URL: gogithub.Ptr(fmt.Sprintf("https://api.github.com/repos/owner/repo/deployments/%d", id)),
+				SHA: gogithub.Ptr(fmt.Sprintf("sha%d", id)),
+			})
+		}
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments?environment=staging&per_page=100", respondJSON(t, deployments))
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments/4/statuses",
+			respondJSON(t, []*gogithub.DeploymentStatus{{State: gogithub.Ptr("success")}}))
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments/3/statuses",
+			respondJSON(t, []*gogithub.DeploymentStatus{{State: gogithub.Ptr("inactive")}}))
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments/2/statuses",
+			respondJSON(t, []*gogithub.DeploymentStatus{}))
+		// The deployment 1 is created by another application in the same environment.
+		sv.Handle("GET /api/v3/repos/owner/repo/deployments/1/statuses",
+			respondJSON(t, []*gogithub.DeploymentStatus{{State: gogithub.Ptr("success")}}))
+		var deactivated []string
+		recordDeactivated := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deactivated = append(deactivated, r.URL.Path)
+			respondJSON(t, gogithub.DeploymentStatus{})(w, r)
+		})
+		sv.Handle("POST /api/v3/repos/owner/repo/deployments/4/statuses", recordDeactivated)
+		sv.Handle("POST /api/v3/repos/owner/repo/deployments/1/statuses", recordDeactivated)
+		c := newMockClient(t, &sv)
+
+		ownedDeploymentURLs := []string{
+			"https://api.github.com/repos/owner/repo/deployments/4",
+			"https://api.github.com/repos/owner/repo/deployments/3",
+			"https://api.github.com/repos/owner/repo/deployments/2",
+		}
+		got, err := c.DeactivateSupersededDeployments(context.TODO(), argocdv1alpha1.Application{}, deploymentURL, ownedDeploymentURLs)
+		if err != nil {
+			t.Fatalf("DeactivateSupersededDeployments error: %s", err)
+		}
+		want := []SupersededDeployment{
+			{URL: "https://api.github.com/repos/owner/repo/deployments/4", Revision: "sha4"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"/api/v3/repos/owner/repo/deployments/4/statuses"}, deactivated); diff != "" {
+			t.Errorf("deactivated mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("disabled by the annotation", func(t *testing.T) {
+		var sv githubmock.Server
+		c := newMockClient(t, &sv)
+		app := argocdv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"argocd-commenter.int128.github.io/deactivate-superseded-deployments": "false",
+				},
+			},
+		}
+		got, err := c.DeactivateSupersededDeployments(context.TODO(), app, deploymentURL, nil)
+		if err != nil {
+			t.Fatalf("DeactivateSupersededDeployments error: %s", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("DeactivateSupersededDeployments wants empty but got %+v", got)
+		}
+	})
+}
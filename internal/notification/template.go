@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/go-logr/logr"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	"github.com/int128/argocd-commenter/internal/github"
+)
+
+// TemplateConfigMapName is the name of ConfigMap containing the user-defined templates.
+// It is looked up in the namespace of Application, like argocd-cm.
+const TemplateConfigMapName = "argocd-commenter-templates"
+
+// Keys of the templates in the ConfigMap.
+const (
+	TemplateKeyCommentOnPhaseRunning   = "comment.phase.Running"
+	TemplateKeyCommentOnPhaseSucceeded = "comment.phase.Succeeded"
+	TemplateKeyCommentOnPhaseFailed    = "comment.phase.Failed"
+	TemplateKeyCommentOnPhaseError     = "comment.phase.Error"
+	TemplateKeyCommentOnHealthHealthy  = "comment.health.Healthy"
+	TemplateKeyCommentOnHealthDegraded = "comment.health.Degraded"
+	TemplateKeyCommentOnRollback       = "comment.rollback"
+
+	TemplateKeyCommentOnProgressDeadlineExceeded          = "comment.progressDeadlineExceeded"
+	TemplateKeyDeploymentStatusOnProgressDeadlineExceeded = "deploymentStatus.progressDeadlineExceeded"
+
+	TemplateKeyDeploymentStatusOnPhaseRunning      = "deploymentStatus.phase.Running"
+	TemplateKeyDeploymentStatusOnPhaseSucceeded    = "deploymentStatus.phase.Succeeded"
+	TemplateKeyDeploymentStatusOnPhaseFailed       = "deploymentStatus.phase.Failed"
+	TemplateKeyDeploymentStatusOnPhaseError        = "deploymentStatus.phase.Error"
+	TemplateKeyDeploymentStatusOnHealthHealthy     = "deploymentStatus.health.Healthy"
+	TemplateKeyDeploymentStatusOnHealthDegraded    = "deploymentStatus.health.Degraded"
+	TemplateKeyDeploymentStatusOnHealthProgressing = "deploymentStatus.health.Progressing"
+	TemplateKeyDeploymentStatusOnHealthSuspended   = "deploymentStatus.health.Suspended"
+	TemplateKeyDeploymentStatusOnDeletion          = "deploymentStatus.deletion"
+)
+
+// TemplateData is the data model passed to a user-defined template.
+type TemplateData struct {
+	// Application is the Argo CD Application.
+	Application argocdv1alpha1.Application
+	// SourceRevision is the source and revision of the sync operation.
+	// It is empty for a deployment status.
+	SourceRevision argocd.SourceRevision
+	// ArgoCDURL is the URL of Argo CD, such as https://argocd.example.com.
+	ArgoCDURL string
+	// ArgoCDApplicationURL is the URL of the Application in Argo CD.
+	ArgoCDApplicationURL string
+	// ExternalURL is the external URL of the Application if available.
+	ExternalURL string
+	// FailedResources are the resources failed to sync, or the resources not healthy.
+	FailedResources []FailedResource
+	// Comparison is the comparison between the previous and current revisions.
+	// It is nil if not available.
+	Comparison *github.Comparison
+	// ResourceSummary is a collapsible section of the resources in the sync result.
+	// It is empty if the sync operation has no result.
+	ResourceSummary string
+}
+
+// FailedResource represents a resource failed to sync or not healthy.
+type FailedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Status is the sync result code or the health status code.
+	Status  string
+	Message string
+}
+
+// Templates is a set of the user-defined templates.
+// A nil Templates falls back to the built-in texts.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// ParseTemplates parses the templates in the data of ConfigMap.
+func ParseTemplates(data map[string]string) (*Templates, error) {
+	templates := make(map[string]*template.Template)
+	for key, text := range data {
+		t, err := template.New(key).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %s: %w", key, err)
+		}
+		templates[key] = t
+	}
+	return &Templates{templates: templates}, nil
+}
+
+// render returns the text rendered from the template of the key.
+// It returns false if the template is not defined.
+func (t *Templates) render(key string, data TemplateData) (string, bool, error) {
+	if t == nil {
+		return "", false, nil
+	}
+	tpl, ok := t.templates[key]
+	if !ok {
+		return "", false, nil
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", false, fmt.Errorf("unable to render template %s: %w", key, err)
+	}
+	return b.String(), true, nil
+}
+
+// renderOrDefault returns the text rendered from the template of the key,
+// or the built-in text if the template is not defined or failed.
+func (t *Templates) renderOrDefault(ctx context.Context, key string, data TemplateData, builtin func() string) string {
+	s, ok, err := t.render(key, data)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "fallback to the built-in text")
+		return builtin()
+	}
+	if !ok {
+		return builtin()
+	}
+	return s
+}
+
+// TemplateLoader loads the user-defined templates.
+type TemplateLoader interface {
+	// LoadTemplates returns the templates in the namespace.
+	// It returns nil if no template is defined.
+	LoadTemplates(ctx context.Context, namespace string) (*Templates, error)
+}
+
+func (c client) loadTemplates(ctx context.Context, namespace string) *Templates {
+	if c.templateLoader == nil {
+		return nil
+	}
+	templates, err := c.templateLoader.LoadTemplates(ctx, namespace)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "unable to load the templates, fallback to the built-in texts")
+		return nil
+	}
+	return templates
+}
+
+func newTemplateData(app argocdv1alpha1.Application, argocdURL string, sourceRevision argocd.SourceRevision, failedResources []FailedResource) TemplateData {
+	return TemplateData{
+		Application:          app,
+		SourceRevision:       sourceRevision,
+		ArgoCDURL:            argocdURL,
+		ArgoCDApplicationURL: fmt.Sprintf("%s/applications/%s", argocdURL, app.Name),
+		ExternalURL:          argocd.GetApplicationExternalURL(app),
+		FailedResources:      failedResources,
+		ResourceSummary:      generateResourceSummary(getSyncResult(app)),
+	}
+}
+
+func getFailedResourcesOnPhaseChanged(app argocdv1alpha1.Application) []FailedResource {
+	if app.Status.OperationState == nil || app.Status.OperationState.SyncResult == nil {
+		return nil
+	}
+	var resources []FailedResource
+	for _, r := range app.Status.OperationState.SyncResult.Resources {
+		switch r.Status {
+		case synccommon.ResultCodeSyncFailed, synccommon.ResultCodePruneSkipped:
+			resources = append(resources, FailedResource{
+				Kind:      r.Kind,
+				Namespace: r.Namespace,
+				Name:      r.Name,
+				Status:    string(r.Status),
+				Message:   r.Message,
+			})
+		}
+	}
+	return resources
+}
+
+// getFailedResourcesOnHealthChanged returns the resources not healthy.
+// If the application is progressing or suspended, it returns the resources still progressing or paused.
+func getFailedResourcesOnHealthChanged(app argocdv1alpha1.Application) []FailedResource {
+	statuses := []health.HealthStatusCode{health.HealthStatusDegraded, health.HealthStatusMissing}
+	switch app.Status.Health.Status {
+	case health.HealthStatusProgressing, health.HealthStatusSuspended:
+		statuses = []health.HealthStatusCode{app.Status.Health.Status}
+	}
+	var resources []FailedResource
+	for _, r := range app.Status.Resources {
+		if r.Health == nil {
+			continue
+		}
+		if slices.Contains(statuses, r.Health.Status) {
+			resources = append(resources, FailedResource{
+				Kind:      r.Kind,
+				Namespace: r.Namespace,
+				Name:      r.Name,
+				Status:    string(r.Health.Status),
+				Message:   r.Health.Message,
+			})
+		}
+	}
+	return resources
+}
+
+func getSyncResult(app argocdv1alpha1.Application) *argocdv1alpha1.SyncOperationResult {
+	if app.Status.OperationState == nil {
+		return nil
+	}
+	return app.Status.OperationState.SyncResult
+}
@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/int128/argocd-commenter/internal/argocd"
+	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generateCommentBodyOnPhaseChanged(t *testing.T) {
+	app := argocdv1alpha1.Application{
+		ObjectMeta: v1meta.ObjectMeta{Name: "app1"},
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Destination: argocdv1alpha1.ApplicationDestination{Name: "production"},
+		},
+		Status: argocdv1alpha1.ApplicationStatus{
+			OperationState: &argocdv1alpha1.OperationState{
+				Phase: synccommon.OperationFailed,
+				SyncResult: &argocdv1alpha1.SyncOperationResult{
+					Resources: argocdv1alpha1.ResourceResults{
+						{Kind: "Deployment", Namespace: "default", Name: "echoserver", Status: synccommon.ResultCodeSyncFailed, Message: "invalid"},
+						{Kind: "Service", Namespace: "default", Name: "echoserver", Status: synccommon.ResultCodeSynced},
+					},
+				},
+			},
+		},
+	}
+	sourceRevision := argocd.SourceRevision{Revision: "main"}
+	const builtinBody = "## :x: Failed to sync [app1](https://argocd.example.com/applications/app1) to main\n" +
+		"- SyncFailed `default/echoserver`: invalid\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>Resources: 0 created, 0 configured, 0 unchanged, 0 pruned, 1 failed, 1 synced</summary>\n" +
+		"\n" +
+		"#### Deployment\n" +
+		"- `default/echoserver`: failed\n" +
+		"#### Service\n" +
+		"- `default/echoserver`: synced\n" +
+		"</details>\n"
+
+	t.Run("built-in", func(t *testing.T) {
+		got := generateCommentBodyOnPhaseChanged(context.TODO(), app, "https://argocd.example.com", sourceRevision, nil, nil)
+		if diff := cmp.Diff(builtinBody, got); diff != "" {
+			t.Errorf("body mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("user-defined", func(t *testing.T) {
+		templates, err := ParseTemplates(map[string]string{
+			TemplateKeyCommentOnPhaseFailed: "Failed {{ .Application.Name }} on {{ .Application.Spec.Destination.Name }} ({{ .ArgoCDApplicationURL }})\n" +
+				"{{ range .FailedResources }}- {{ .Kind }} {{ .Namespace }}/{{ .Name }}: {{ .Message }}\n{{ end }}",
+		})
+		if err != nil {
+			t.Fatalf("ParseTemplates: %s", err)
+		}
+		got := generateCommentBodyOnPhaseChanged(context.TODO(), app, "https://argocd.example.com", sourceRevision, nil, templates)
+		const want = "Failed app1 on production (https://argocd.example.com/applications/app1)\n" +
+			"- Deployment default/echoserver: invalid\n"
+		if want != got {
+			t.Errorf("body wants %q but was %q", want, got)
+		}
+	})
+
+	t.Run("fallback on error", func(t *testing.T) {
+		templates, err := ParseTemplates(map[string]string{
+			TemplateKeyCommentOnPhaseFailed: "{{ .NoSuchField }}",
+		})
+		if err != nil {
+			t.Fatalf("ParseTemplates: %s", err)
+		}
+		got := generateCommentBodyOnPhaseChanged(context.TODO(), app, "https://argocd.example.com", sourceRevision, nil, templates)
+		if diff := cmp.Diff(builtinBody, got); diff != "" {
+			t.Errorf("body mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestParseTemplates(t *testing.T) {
+	_, err := ParseTemplates(map[string]string{TemplateKeyCommentOnHealthHealthy: "{{ .Application"})
+	if err == nil {
+		t.Errorf("ParseTemplates wants an error but was nil")
+	}
+}
@@ -0,0 +1,32 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigMapTemplateLoader loads the templates from ConfigMap.
+type ConfigMapTemplateLoader struct {
+	Client crclient.Reader
+}
+
+func (l ConfigMapTemplateLoader) LoadTemplates(ctx context.Context, namespace string) (*Templates, error) {
+	var cm corev1.ConfigMap
+	err := l.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: TemplateConfigMapName}, &cm)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ConfigMap %s: %w", TemplateConfigMapName, err)
+	}
+	templates, err := ParseTemplates(cm.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap %s: %w", TemplateConfigMapName, err)
+	}
+	return templates, nil
+}